@@ -0,0 +1,327 @@
+// Package export writes kalshi market data and portfolio history as CSV and
+// Parquet, e.g. for pandas or DuckDB.
+//
+// Every kind of record has a row type whose fields, in order, are the
+// columns of both formats. Cents are integers and times are Timestamps: RFC
+// 3339 strings in UTC in CSV and microsecond timestamps in Parquet. Missing
+// times are empty in CSV and null in Parquet. Writers stream,
+// so exports needn't fit in memory.
+//
+// It lives apart from package kalshi so that only programs that export
+// depend on Parquet.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Timestamp is a time in microseconds since the Unix epoch. Zero is a
+// missing time.
+type Timestamp int64
+
+// TimestampOf returns the Timestamp of t, zero if t is zero.
+func TimestampOf(t time.Time) Timestamp {
+	if t.IsZero() {
+		return 0
+	}
+	return Timestamp(t.UnixMicro())
+}
+
+// Time returns t in UTC, or the zero time if t is zero.
+func (t Timestamp) Time() time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(int64(t)).UTC()
+}
+
+// TradeRow is a kalshi.Trade.
+type TradeRow struct {
+	TradeID     string    `parquet:"trade_id"`
+	Ticker      string    `parquet:"ticker"`
+	CreatedTime Timestamp `parquet:"created_time,timestamp(microsecond),optional"`
+	YesPrice    int64     `parquet:"yes_price"`
+	NoPrice     int64     `parquet:"no_price"`
+	Count       int64     `parquet:"count"`
+	TakerSide   string    `parquet:"taker_side"`
+}
+
+// TradeRowOf returns the row of t.
+func TradeRowOf(t kalshi.Trade) TradeRow {
+	return TradeRow{
+		TradeID:     t.TradeID,
+		Ticker:      t.Ticker,
+		CreatedTime: TimestampOf(t.CreatedTime),
+		YesPrice:    int64(t.YesPrice),
+		NoPrice:     int64(t.NoPrice),
+		Count:       int64(t.Count),
+		TakerSide:   string(t.TakerSide),
+	}
+}
+
+// HistoryRow is a kalshi.MarketHistory of a market.
+type HistoryRow struct {
+	Ticker       string    `parquet:"ticker"`
+	Ts           Timestamp `parquet:"ts,timestamp(microsecond),optional"`
+	YesBid       int64     `parquet:"yes_bid"`
+	YesAsk       int64     `parquet:"yes_ask"`
+	NoBid        int64     `parquet:"no_bid"`
+	NoAsk        int64     `parquet:"no_ask"`
+	YesPrice     int64     `parquet:"yes_price"`
+	Volume       int64     `parquet:"volume"`
+	OpenInterest int64     `parquet:"open_interest"`
+}
+
+// HistoryRowOf returns the row of h, of the market with ticker.
+func HistoryRowOf(ticker string, h kalshi.MarketHistory) HistoryRow {
+	return HistoryRow{
+		Ticker:       ticker,
+		Ts:           TimestampOf(h.Ts.Time()),
+		YesBid:       int64(h.YesBid),
+		YesAsk:       int64(h.YesAsk),
+		NoBid:        int64(h.NoBid),
+		NoAsk:        int64(h.NoAsk),
+		YesPrice:     int64(h.YesPrice),
+		Volume:       int64(h.Volume),
+		OpenInterest: int64(h.OpenInterest),
+	}
+}
+
+// FillRow is a kalshi.Fill.
+type FillRow struct {
+	TradeID     string    `parquet:"trade_id"`
+	OrderID     string    `parquet:"order_id"`
+	Ticker      string    `parquet:"ticker"`
+	CreatedTime Timestamp `parquet:"created_time,timestamp(microsecond),optional"`
+	Side        string    `parquet:"side"`
+	Action      string    `parquet:"action"`
+	YesPrice    int64     `parquet:"yes_price"`
+	NoPrice     int64     `parquet:"no_price"`
+	Count       int64     `parquet:"count"`
+	IsTaker     bool      `parquet:"is_taker"`
+}
+
+// FillRowOf returns the row of f.
+func FillRowOf(f kalshi.Fill) FillRow {
+	return FillRow{
+		TradeID:     f.TradeID,
+		OrderID:     f.OrderID,
+		Ticker:      f.Ticker,
+		CreatedTime: TimestampOf(f.CreatedTime),
+		Side:        string(f.Side),
+		Action:      string(f.Action),
+		YesPrice:    int64(f.YesPrice),
+		NoPrice:     int64(f.NoPrice),
+		Count:       int64(f.Count),
+		IsTaker:     f.IsTaker,
+	}
+}
+
+// SettlementRow is a kalshi.Settlement.
+type SettlementRow struct {
+	Ticker       string    `parquet:"ticker"`
+	SettledTime  Timestamp `parquet:"settled_time,timestamp(microsecond),optional"`
+	MarketResult string    `parquet:"market_result"`
+	YesCount     int64     `parquet:"yes_count"`
+	YesTotalCost int64     `parquet:"yes_total_cost"`
+	NoCount      int64     `parquet:"no_count"`
+	NoTotalCost  int64     `parquet:"no_total_cost"`
+	Revenue      int64     `parquet:"revenue"`
+}
+
+// SettlementRowOf returns the row of s.
+func SettlementRowOf(s kalshi.Settlement) SettlementRow {
+	return SettlementRow{
+		Ticker:       s.Ticker,
+		SettledTime:  TimestampOf(s.SettledTime),
+		MarketResult: s.MarketResult,
+		YesCount:     int64(s.YesCount),
+		YesTotalCost: int64(s.YesTotalCost),
+		NoCount:      int64(s.NoCount),
+		NoTotalCost:  int64(s.NoTotalCost),
+		Revenue:      int64(s.Revenue),
+	}
+}
+
+// OrderBookRow is a price level of an order book snapshot.
+type OrderBookRow struct {
+	Ticker   string    `parquet:"ticker"`
+	LoadedAt Timestamp `parquet:"loaded_at,timestamp(microsecond),optional"`
+	// Side is the side of the bids, "yes" or "no".
+	Side     string `parquet:"side"`
+	Price    int64  `parquet:"price"`
+	Quantity int64  `parquet:"quantity"`
+}
+
+// OrderBookRowsOf returns the rows of the levels of book, Yes bids first.
+func OrderBookRowsOf(book *kalshi.StreamOrderBook) []OrderBookRow {
+	rows := make([]OrderBookRow, 0, len(book.YesBids)+len(book.NoBids))
+	for _, side := range []struct {
+		side kalshi.Side
+		bids kalshi.OrderBookBids
+	}{{kalshi.Yes, book.YesBids}, {kalshi.No, book.NoBids}} {
+		for _, bid := range side.bids {
+			rows = append(rows, OrderBookRow{
+				Ticker:   book.MarketID,
+				LoadedAt: TimestampOf(book.LoadedAt),
+				Side:     string(side.side),
+				Price:    int64(bid.Price),
+				Quantity: int64(bid.Quantity),
+			})
+		}
+	}
+	return rows
+}
+
+// Writer writes rows of type T. Close must be called to complete the
+// output; it doesn't close the underlying io.Writer.
+type Writer[T any] interface {
+	Write(rows ...T) error
+	Close() error
+}
+
+// WriteAll writes the rows converted from seq and closes w, stopping at
+// the first error.
+func WriteAll[S, T any](w Writer[T], seq iter.Seq2[S, error], convert func(S) T) error {
+	for s, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := w.Write(convert(s)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// Slice returns an iterator over s, for WriteAll.
+func Slice[S any](s []S) iter.Seq2[S, error] {
+	return func(yield func(S, error) bool) {
+		for _, v := range s {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+type csvWriter[T any] struct {
+	w      *csv.Writer
+	record []string
+}
+
+// NewCSVWriter creates a Writer of CSV with a header of the column names of
+// T, one of the row types of this package.
+func NewCSVWriter[T any](w io.Writer) (Writer[T], error) {
+	columns, err := csvColumns(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	cw := &csvWriter[T]{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, fmt.Errorf("csv.Write: %w", err)
+	}
+	return cw, nil
+}
+
+// csvColumns returns the column names of t, which must have fields of the
+// kinds supported by csvValue only.
+func csvColumns(t reflect.Type) ([]string, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v isn't a struct", t)
+	}
+	columns := make([]string, t.NumField())
+	for i := range columns {
+		f := t.Field(i)
+		switch f.Type.Kind() {
+		case reflect.String, reflect.Int64, reflect.Bool:
+		default:
+			return nil, fmt.Errorf("%v.%s has unsupported type %v", t, f.Name, f.Type)
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("parquet"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		columns[i] = name
+	}
+	return columns, nil
+}
+
+func csvValue(v reflect.Value) string {
+	if t, ok := v.Interface().(Timestamp); ok {
+		if t == 0 {
+			return ""
+		}
+		return t.Time().Format(time.RFC3339Nano)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	default:
+		return strconv.FormatBool(v.Bool())
+	}
+}
+
+func (w *csvWriter[T]) Write(rows ...T) error {
+	for i := range rows {
+		v := reflect.ValueOf(&rows[i]).Elem()
+		for j := range w.record {
+			w.record[j] = csvValue(v.Field(j))
+		}
+		if err := w.w.Write(w.record); err != nil {
+			return fmt.Errorf("csv.Write: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter[T]) Close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("csv.Flush: %w", err)
+	}
+	return nil
+}
+
+// ParquetRowGroupSize is the number of rows buffered by a Parquet Writer
+// before they are written as a row group.
+const ParquetRowGroupSize = 64 * 1024
+
+type parquetWriter[T any] struct {
+	w *parquet.GenericWriter[T]
+}
+
+// NewParquetWriter creates a Writer of Parquet with the schema of T, one of
+// the row types of this package, compressed with Zstandard.
+func NewParquetWriter[T any](w io.Writer) Writer[T] {
+	return &parquetWriter[T]{w: parquet.NewGenericWriter[T](w,
+		parquet.Compression(&parquet.Zstd),
+		parquet.MaxRowsPerRowGroup(ParquetRowGroupSize),
+	)}
+}
+
+func (w *parquetWriter[T]) Write(rows ...T) error {
+	if _, err := w.w.Write(rows); err != nil {
+		return fmt.Errorf("parquet.Write: %w", err)
+	}
+	return nil
+}
+
+func (w *parquetWriter[T]) Close() error {
+	if err := w.w.Close(); err != nil {
+		return fmt.Errorf("parquet.Close: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+var testTrades = []kalshi.Trade{
+	{
+		TradeID:     "t1",
+		Ticker:      "A",
+		CreatedTime: time.Date(2024, 1, 2, 10, 0, 0, 500000000, time.FixedZone("EST", -5*3600)),
+		YesPrice:    40,
+		NoPrice:     60,
+		Count:       3,
+		TakerSide:   kalshi.Yes,
+	},
+	{TradeID: "t2", Ticker: "A", CreatedTime: time.Date(2024, 1, 2, 15, 1, 0, 0, time.UTC), YesPrice: 41, NoPrice: 59, Count: 1, TakerSide: kalshi.No},
+}
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w, err := NewCSVWriter[TradeRow](&buf)
+	require.NoError(t, err)
+	require.NoError(t, WriteAll(w, Slice(testTrades), TradeRowOf))
+	require.Equal(t, `trade_id,ticker,created_time,yes_price,no_price,count,taker_side
+t1,A,2024-01-02T15:00:00.5Z,40,60,3,yes
+t2,A,2024-01-02T15:01:00Z,41,59,1,no
+`, buf.String())
+
+	buf.Reset()
+	fw, err := NewCSVWriter[FillRow](&buf)
+	require.NoError(t, err)
+	require.NoError(t, fw.Write(FillRowOf(kalshi.Fill{TradeID: "t1", OrderID: "o1", Ticker: "A", Side: kalshi.Yes, Action: kalshi.Buy, YesPrice: 40, NoPrice: 60, Count: 2, IsTaker: true})))
+	require.NoError(t, fw.Close())
+	require.Equal(t, `trade_id,order_id,ticker,created_time,side,action,yes_price,no_price,count,is_taker
+t1,o1,A,,yes,buy,40,60,2,true
+`, buf.String())
+
+	_, err = NewCSVWriter[kalshi.Trade](&buf)
+	require.Error(t, err)
+}
+
+func TestParquet(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAll(NewParquetWriter[TradeRow](&buf), Slice(testTrades), TradeRowOf))
+
+	rows, err := parquet.Read[TradeRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, TradeRowOf(testTrades[0]), rows[0])
+	require.Equal(t, time.UTC, rows[0].CreatedTime.Time().Location())
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var columns []string
+	for _, field := range f.Schema().Fields() {
+		columns = append(columns, field.Name())
+	}
+	require.Equal(t, []string{"trade_id", "ticker", "created_time", "yes_price", "no_price", "count", "taker_side"}, columns)
+
+	// Missing times are null, as they are empty in CSV.
+	buf.Reset()
+	require.NoError(t, WriteAll(NewParquetWriter[FillRow](&buf), Slice([]kalshi.Fill{{TradeID: "t1"}}), FillRowOf))
+	f, err = parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	page, err := f.RowGroups()[0].ColumnChunks()[3].Pages().ReadPage()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, page.NumNulls())
+}
+
+func TestOrderBookRows(t *testing.T) {
+	t.Parallel()
+
+	loaded := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	rows := OrderBookRowsOf(&kalshi.StreamOrderBook{
+		MarketID: "A",
+		LoadedAt: loaded,
+		OrderBook: kalshi.OrderBook{
+			YesBids: kalshi.OrderBookBids{{Price: 40, Quantity: 10}},
+			NoBids:  kalshi.OrderBookBids{{Price: 55, Quantity: 3}, {Price: 50, Quantity: 7}},
+		},
+	})
+	require.Equal(t, []OrderBookRow{
+		{Ticker: "A", LoadedAt: TimestampOf(loaded), Side: "yes", Price: 40, Quantity: 10},
+		{Ticker: "A", LoadedAt: TimestampOf(loaded), Side: "no", Price: 55, Quantity: 3},
+		{Ticker: "A", LoadedAt: TimestampOf(loaded), Side: "no", Price: 50, Quantity: 7},
+	}, rows)
+}
+
+func TestWriteAllError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	seq := func(yield func(kalshi.Settlement, error) bool) {
+		if yield(kalshi.Settlement{Ticker: "A"}, nil) {
+			yield(kalshi.Settlement{}, boom)
+		}
+	}
+	var buf bytes.Buffer
+	w, err := NewCSVWriter[SettlementRow](&buf)
+	require.NoError(t, err)
+	require.ErrorIs(t, WriteAll(w, seq, SettlementRowOf), boom)
+}
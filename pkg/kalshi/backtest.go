@@ -0,0 +1,273 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MarketEvent is an order book or a trade of recorded market data.
+type MarketEvent struct {
+	Time  time.Time
+	Book  *StreamOrderBook
+	Trade *Trade
+}
+
+// BookEvent returns the MarketEvent of a book, at its LoadedAt.
+func BookEvent(b *StreamOrderBook) MarketEvent {
+	return MarketEvent{Time: b.LoadedAt, Book: b}
+}
+
+// TradeEvent returns the MarketEvent of a trade, at its CreatedTime.
+func TradeEvent(t Trade) MarketEvent {
+	return MarketEvent{Time: t.CreatedTime, Trade: &t}
+}
+
+// Ticker returns the market of the event.
+func (e MarketEvent) Ticker() string {
+	if e.Book != nil {
+		return e.Book.MarketID
+	}
+	if e.Trade != nil {
+		return e.Trade.Ticker
+	}
+	return ""
+}
+
+// ReplayBooks returns the books of marketTicker in a recording replayed by
+// feed, e.g. one created by ReplayFeed with a speed of 0, as MarketEvents.
+func ReplayBooks(ctx context.Context, feed *Feed, marketTicker string) ([]MarketEvent, error) {
+	books := make(chan *StreamOrderBook)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(books)
+		errs <- feed.Book(ctx, marketTicker, books)
+	}()
+
+	var events []MarketEvent
+	for b := range books {
+		events = append(events, BookEvent(b))
+	}
+	if err := <-errs; err != nil && !errors.Is(err, io.EOF) {
+		return events, fmt.Errorf("feed.Book: %w", err)
+	}
+	return events, nil
+}
+
+// BacktestStrategy is called with every event of a backtest, after the
+// exchange has applied it, and trades through client.
+type BacktestStrategy func(ctx context.Context, client KalshiClientLogic, event MarketEvent) error
+
+// EquityPoint is a sample of a backtest's account.
+type EquityPoint struct {
+	Time    time.Time
+	Balance Cents
+	// Equity is the balance plus the open positions valued at the mid
+	// price.
+	Equity Cents
+	// Exposure is the cost of the open positions.
+	Exposure Cents
+}
+
+// BacktestReport is the outcome of a backtest.
+type BacktestReport struct {
+	Start, End      time.Time
+	StartingBalance Cents
+	FinalBalance    Cents
+	PnL             PnLReport
+	// MaxDrawdown is the largest decline of equity from a previous peak.
+	MaxDrawdown Cents
+	SimulatedStats
+	// FillRatio is the share of the contracts ordered that were filled.
+	FillRatio float64
+	Equity    []EquityPoint
+	Fills     []Fill
+}
+
+// Backtester runs a strategy over recorded market data against a
+// SimulatedExchange.
+type Backtester struct {
+	// SampleInterval is the minimum time between points of the equity
+	// curve, besides the final one. Zero samples after every event.
+	SampleInterval time.Duration
+	// PnLBucket is the size of the buckets of the PnL report. Zero omits
+	// them.
+	PnLBucket time.Duration
+	// TimerInterval is the interval of the OnTimer calls of RunStrategy,
+	// in the time of the events. Zero disables them.
+	TimerInterval time.Duration
+
+	exchange *SimulatedExchange
+	balance  Cents
+}
+
+// NewBacktester creates a Backtester with a starting balance, trading
+// markets, whose Results settle them. PnL is computed FIFO.
+func NewBacktester(balance Cents, fees FeeSchedule, markets ...Market) *Backtester {
+	return &Backtester{
+		exchange: NewSimulatedExchange(balance, fees, FIFO, markets...),
+		balance:  balance,
+	}
+}
+
+// Exchange returns the simulated exchange the strategy trades on.
+func (b *Backtester) Exchange() *SimulatedExchange {
+	return b.exchange
+}
+
+// Run replays events in time order, calling strategy after each, then
+// settles the markets with a result and reports. Events at the same time
+// keep their order. Run stops at the first error of strategy.
+func (b *Backtester) Run(ctx context.Context, events []MarketEvent, strategy BacktestStrategy) (*BacktestReport, error) {
+	return b.run(ctx, events, func(ev MarketEvent) error {
+		b.exchange.Apply(ev)
+		if strategy == nil {
+			return nil
+		}
+		return strategy(ctx, b.exchange, ev)
+	}, nil)
+}
+
+// RunStrategy is like Run but runs a Strategy as a Runner would, on the
+// exchange's clock. Books and trades are passed to OnBook and OnTrade once
+// the exchange has applied them, followed by the fills and order updates
+// since, and OnTimer is called every TimerInterval. Once the events are
+// replayed, OnShutdown is called and the orders left open are canceled,
+// before the markets settle.
+func (b *Backtester) RunStrategy(ctx context.Context, events []MarketEvent, strategy Strategy) (*BacktestReport, error) {
+	var tickers []string
+	for _, ev := range events {
+		if t := ev.Ticker(); t != "" && !slices.Contains(tickers, t) {
+			tickers = append(tickers, t)
+		}
+	}
+	sort.Strings(tickers)
+	r := NewRunner(b.exchange, nil, strategy, tickers...)
+	r.TimerInterval = b.TimerInterval
+	r.orders.now = b.exchange.Now
+
+	var nextTimer time.Time
+	step := func(ev MarketEvent) error {
+		if nextTimer.IsZero() {
+			nextTimer = ev.Time.Add(r.TimerInterval)
+		}
+		for ; r.TimerInterval > 0 && !nextTimer.After(ev.Time); nextTimer = nextTimer.Add(r.TimerInterval) {
+			// The clock advances to the timer first.
+			b.exchange.Apply(MarketEvent{Time: nextTimer})
+			if err := r.strategy.OnTimer(ctx, r, nextTimer); err != nil {
+				return err
+			}
+			if err := b.report(ctx, r); err != nil {
+				return err
+			}
+		}
+
+		b.exchange.Apply(ev)
+		var err error
+		switch {
+		case ev.Book != nil:
+			err = r.strategy.OnBook(ctx, r, ev.Book)
+		case ev.Trade != nil:
+			err = r.strategy.OnTrade(ctx, r, *ev.Trade)
+		}
+		if err != nil {
+			return err
+		}
+		return b.report(ctx, r)
+	}
+	finish := func() error {
+		if err := r.shutdown(ctx); err != nil {
+			return fmt.Errorf("r.shutdown: %w", err)
+		}
+		return nil
+	}
+	return b.run(ctx, events, step, finish)
+}
+
+// report passes r's strategy the fills and order updates of the exchange
+// since the last report, including those caused by the strategy's own
+// callbacks.
+func (b *Backtester) report(ctx context.Context, r *Runner) error {
+	for {
+		fills, orders := b.exchange.updates()
+		for _, fill := range fills {
+			if !r.orders.ApplyFill(fill) {
+				continue
+			}
+			if err := r.strategy.OnFill(ctx, r, fill); err != nil {
+				return err
+			}
+		}
+		for i := range orders {
+			r.orders.ApplyOrder(&orders[i])
+		}
+		if err := r.reportOrders(ctx); err != nil {
+			return err
+		}
+		if len(fills) == 0 && len(orders) == 0 {
+			return nil
+		}
+	}
+}
+
+// run replays events in time order through step, calls finish, if any, and
+// then settles the markets with a result and reports.
+func (b *Backtester) run(ctx context.Context, events []MarketEvent, step func(ev MarketEvent) error, finish func() error) (*BacktestReport, error) {
+	events = append([]MarketEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+	report := &BacktestReport{StartingBalance: b.balance}
+	peak := b.balance
+	sample := func(t time.Time) {
+		balance, _ := b.exchange.GetBalance(ctx)
+		equity, exposure := b.exchange.Equity()
+		report.Equity = append(report.Equity, EquityPoint{
+			Time:     t,
+			Balance:  balance,
+			Equity:   equity,
+			Exposure: exposure,
+		})
+		peak = max(peak, equity)
+		report.MaxDrawdown = max(report.MaxDrawdown, peak-equity)
+	}
+
+	for _, ev := range events {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := step(ev); err != nil {
+			return nil, fmt.Errorf("strategy at %v: %w", ev.Time, err)
+		}
+		if n := len(report.Equity); n == 0 || ev.Time.Sub(report.Equity[n-1].Time) >= b.SampleInterval {
+			sample(ev.Time)
+		}
+	}
+
+	if finish != nil {
+		if err := finish(); err != nil {
+			return nil, err
+		}
+	}
+	b.exchange.SettleAll()
+	if len(events) > 0 {
+		report.Start, report.End = events[0].Time, events[len(events)-1].Time
+		// The final point is taken after settlement.
+		if n := len(report.Equity); report.Equity[n-1].Time.Equal(report.End) {
+			report.Equity = report.Equity[:n-1]
+		}
+		sample(report.End)
+	}
+
+	report.FinalBalance, _ = b.exchange.GetBalance(ctx)
+	report.PnL = b.exchange.PnL().Report(time.Time{}, time.Time{}, b.PnLBucket)
+	report.SimulatedStats = b.exchange.Stats()
+	if report.ContractsOrdered > 0 {
+		report.FillRatio = float64(report.ContractsFilled) / float64(report.ContractsOrdered)
+	}
+	report.Fills = b.exchange.Fills()
+	return report, nil
+}
@@ -0,0 +1,322 @@
+package kalshi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testBookEvent(ticker string, at time.Time, yes, no OrderBookBids) MarketEvent {
+	return BookEvent(&StreamOrderBook{
+		OrderBook: OrderBook{YesBids: yes, NoBids: no},
+		LoadedAt:  at,
+		MarketID:  ticker,
+	})
+}
+
+func TestSimulatedExchangeTaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	e := NewSimulatedExchange(10000, DefaultFeeSchedule(), FIFO, Market{Ticker: "KX-A"})
+	e.Apply(testBookEvent("KX-A", t0, OrderBookBids{{Price: 40, Quantity: 5}}, OrderBookBids{{Price: 55, Quantity: 3}, {Price: 58, Quantity: 2}}))
+
+	// Buying 4 Yes at up to 45 walks the asks at 42 and 45.
+	order, err := e.CreateOrder(ctx, CreateOrderRequest{Ticker: "KX-A", Action: Buy, Side: Yes, Count: 4, YesPrice: 45, Type: LimitOrder})
+	require.NoError(t, err)
+	require.Equal(t, Executed, order.Status)
+	require.Equal(t, 4, order.TakerFillCount)
+	require.Equal(t, Cents(84+90), order.TakerFillCost)
+	require.Equal(t, Cents(4+4), order.TakerFees)
+	balance, err := e.GetBalance(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Cents(10000-84-4-90-4), balance)
+
+	// The liquidity taken is gone until the next book.
+	book, err := e.MarketOrderBook(ctx, "KX-A")
+	require.NoError(t, err)
+	require.Equal(t, OrderBookBids{{Price: 55, Quantity: 1}}, book.NoBids)
+	m, err := e.Market(ctx, "KX-A")
+	require.NoError(t, err)
+	require.Equal(t, Cents(45), m.YesAsk)
+
+	// The remainder of an immediate-or-cancel order is canceled.
+	order, err = e.CreateOrder(ctx, CreateOrderRequest{Ticker: "KX-A", Action: Buy, Side: Yes, Count: 5, YesPrice: 45, Type: LimitOrder, Expiration: OrderExecuteImmediateOrCancel()})
+	require.NoError(t, err)
+	require.Equal(t, Canceled, order.Status)
+	require.Equal(t, 1, order.TakerFillCount)
+
+	// Selling Yes nets the position, paying out 100 per pair.
+	balance, _ = e.GetBalance(ctx)
+	order, err = e.CreateOrder(ctx, CreateOrderRequest{Ticker: "KX-A", Action: Sell, Side: Yes, Count: 2, YesPrice: 40, Type: LimitOrder})
+	require.NoError(t, err)
+	require.Equal(t, Executed, order.Status)
+	after, _ := e.GetBalance(ctx)
+	require.Equal(t, balance+2*40-DefaultFeeSchedule().Fee("KX-A", 40, 2, true), after)
+	positions, err := e.GetPositions(ctx, PositionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, positions.MarketPositions, 1)
+	require.Equal(t, 3, positions.MarketPositions[0].Position)
+
+	fills, err := e.GetFills(ctx, FillsRequest{Ticker: "KX-A"})
+	require.NoError(t, err)
+	require.Len(t, fills.Fills, 4)
+	require.Equal(t, Sell, fills.Fills[0].Action)
+	require.Equal(t, Cents(40), fills.Fills[0].YesPrice)
+
+	_, err = e.CreateOrder(ctx, CreateOrderRequest{Ticker: "KX-A", Action: Buy, Side: No, Count: 1000, NoPrice: 50, Type: LimitOrder})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	_, err = e.Series(ctx, "KX")
+	require.ErrorIs(t, err, ErrNotSimulated)
+}
+
+func TestSimulatedExchangeQueue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	e := NewSimulatedExchange(10000, FeeSchedule{}, FIFO, Market{Ticker: "A"})
+	e.Apply(testBookEvent("A", t0, OrderBookBids{{Price: 40, Quantity: 10}}, OrderBookBids{{Price: 55, Quantity: 5}}))
+
+	order, err := e.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 3, YesPrice: 40, Type: LimitOrder})
+	require.NoError(t, err)
+	require.Equal(t, Resting, order.Status)
+	require.Equal(t, 10, order.QueuePosition)
+
+	// Sellers of Yes at 40 first fill the 10 contracts ahead.
+	trade := func(id string, at time.Time, count int) MarketEvent {
+		return TradeEvent(Trade{TradeID: id, Ticker: "A", TakerSide: No, YesPrice: 40, NoPrice: 60, Count: count, CreatedTime: at})
+	}
+	e.Apply(trade("t1", t0.Add(time.Second), 8))
+	order, err = e.GetOrder(ctx, order.OrderID)
+	require.NoError(t, err)
+	require.Equal(t, 0, order.MakerFillCount)
+	require.Equal(t, 2, order.QueuePosition)
+
+	e.Apply(trade("t2", t0.Add(2*time.Second), 4))
+	order, _ = e.GetOrder(ctx, order.OrderID)
+	require.Equal(t, 2, order.MakerFillCount)
+	require.Equal(t, 1, order.RemainingCount)
+	require.Equal(t, Resting, order.Status)
+
+	// A book offering Yes at 39 crosses the order, which fills at its price.
+	e.Apply(testBookEvent("A", t0.Add(3*time.Second), nil, OrderBookBids{{Price: 61, Quantity: 5}}))
+	order, _ = e.GetOrder(ctx, order.OrderID)
+	require.Equal(t, Executed, order.Status)
+	require.Equal(t, 3, order.MakerFillCount)
+	balance, _ := e.GetBalance(ctx)
+	require.Equal(t, Cents(10000-3*40), balance)
+
+	// Orders expire on the exchange's clock.
+	at := Timestamp(t0.Add(time.Minute))
+	order, err = e.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: No, Count: 1, NoPrice: 10, Type: LimitOrder, Expiration: &at})
+	require.NoError(t, err)
+	require.Equal(t, Resting, order.Status)
+	e.Apply(trade("t3", t0.Add(time.Minute), 1))
+	order, _ = e.GetOrder(ctx, order.OrderID)
+	require.Equal(t, Canceled, order.Status)
+	_, err = e.CancelOrder(ctx, order.OrderID)
+	require.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestSimulatedExchangeQueuePriority(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	e := NewSimulatedExchange(10000, FeeSchedule{}, FIFO, Market{Ticker: "A"})
+	e.Apply(testBookEvent("A", t0, OrderBookBids{{Price: 40, Quantity: 10}}, OrderBookBids{{Price: 55, Quantity: 5}}))
+
+	better, err := e.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 2, YesPrice: 41, Type: LimitOrder})
+	require.NoError(t, err)
+	order, err := e.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 3, YesPrice: 40, Type: LimitOrder})
+	require.NoError(t, err)
+
+	// The order at 41 takes 2 of the trade, leaving 6 for the queue at 40.
+	e.Apply(TradeEvent(Trade{TradeID: "t1", Ticker: "A", TakerSide: No, YesPrice: 40, NoPrice: 60, Count: 8, CreatedTime: t0.Add(time.Second)}))
+	better, _ = e.GetOrder(ctx, better.OrderID)
+	require.Equal(t, Executed, better.Status)
+	order, _ = e.GetOrder(ctx, order.OrderID)
+	require.Equal(t, 0, order.MakerFillCount)
+	require.Equal(t, 4, order.QueuePosition)
+
+	// Raising the count sends the order to the back of the queue.
+	e.updates()
+	resp, err := e.AmendOrder(ctx, order.OrderID, AmendOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 5, YesPrice: 40})
+	require.NoError(t, err)
+	require.Equal(t, 10, resp.Order.QueuePosition)
+	_, orders := e.updates()
+	require.Len(t, orders, 1)
+	require.Equal(t, 5, orders[0].RemainingCount)
+}
+
+func TestSimulatedExchangeAmend(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	e := NewSimulatedExchange(1000, FeeSchedule{}, FIFO, Market{Ticker: "A"})
+	e.Apply(testBookEvent("A", t0, OrderBookBids{{Price: 40, Quantity: 10}}, OrderBookBids{{Price: 50, Quantity: 5}}))
+
+	order, err := e.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 10, YesPrice: 40, Type: LimitOrder})
+	require.NoError(t, err)
+	other, err := e.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: No, Count: 10, NoPrice: 40, Type: LimitOrder})
+	require.NoError(t, err)
+
+	// The 800 reserved leave room for 5 more contracts at 40.
+	amend := AmendOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 16, YesPrice: 40}
+	_, err = e.AmendOrder(ctx, order.OrderID, amend)
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	amend.Count = 15
+	resp, err := e.AmendOrder(ctx, order.OrderID, amend)
+	require.NoError(t, err)
+	require.Equal(t, 15, resp.Order.RemainingCount)
+	require.Equal(t, 25, e.Stats().ContractsOrdered)
+
+	// Canceled orders release their reservation.
+	_, err = e.CancelOrder(ctx, other.OrderID)
+	require.NoError(t, err)
+	amend.Count, amend.YesPrice = 20, 45
+	resp, err = e.AmendOrder(ctx, order.OrderID, amend)
+	require.NoError(t, err)
+	require.Equal(t, Resting, resp.Order.Status)
+	require.Equal(t, 30, e.Stats().ContractsOrdered)
+	positions, err := e.GetPositions(ctx, PositionsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 20, positions.MarketPositions[0].RestingOrdersCount)
+}
+
+func TestBacktester(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	b := NewBacktester(10000, DefaultFeeSchedule(), Market{
+		Ticker:         "KX-A",
+		EventTicker:    "KX",
+		ExpirationTime: t0.Add(10 * time.Minute),
+		Result:         "no",
+	})
+
+	events := []MarketEvent{
+		TradeEvent(Trade{TradeID: "t1", Ticker: "KX-A", TakerSide: Yes, YesPrice: 30, NoPrice: 70, Count: 1, CreatedTime: t0.Add(10 * time.Minute)}),
+		testBookEvent("KX-A", t0.Add(5*time.Minute), OrderBookBids{{Price: 20, Quantity: 10}}, OrderBookBids{{Price: 69, Quantity: 10}}),
+		testBookEvent("KX-A", t0, OrderBookBids{{Price: 40, Quantity: 10}}, OrderBookBids{{Price: 55, Quantity: 20}}),
+	}
+	report, err := b.Run(ctx, events, func(ctx context.Context, client KalshiClientLogic, ev MarketEvent) error {
+		m, err := client.Market(ctx, "KX-A")
+		require.NoError(t, err)
+		// The result is only known once the market settles.
+		if ev.Time.Before(t0.Add(10 * time.Minute)) {
+			require.Empty(t, m.Result)
+		} else {
+			require.Equal(t, "no", m.Result)
+		}
+		if !ev.Time.Equal(t0) {
+			return nil
+		}
+		if _, err := client.CreateOrder(ctx, CreateOrderRequest{Ticker: "KX-A", Action: Buy, Side: Yes, Count: 10, YesPrice: 45, Type: LimitOrder}); err != nil {
+			return err
+		}
+		_, err = client.CreateOrder(ctx, CreateOrderRequest{Ticker: "KX-A", Action: Buy, Side: Yes, Count: 10, YesPrice: 30, Type: LimitOrder})
+		return err
+	})
+	require.NoError(t, err)
+
+	fee := DefaultFeeSchedule().Fee("KX-A", 45, 10, true)
+	require.Equal(t, t0, report.Start)
+	require.Equal(t, t0.Add(10*time.Minute), report.End)
+	require.Equal(t, Cents(10000-450)-fee, report.FinalBalance)
+	require.Equal(t, []EquityPoint{
+		{Time: t0, Balance: 9550 - fee, Equity: 9550 - fee + 10*42, Exposure: 450},
+		{Time: t0.Add(5 * time.Minute), Balance: 9550 - fee, Equity: 9550 - fee + 10*25, Exposure: 450},
+		{Time: t0.Add(10 * time.Minute), Balance: 9550 - fee, Equity: 9550 - fee},
+	}, report.Equity)
+	require.Equal(t, 450+fee, report.MaxDrawdown)
+	require.Equal(t, 2, report.Orders)
+	require.Equal(t, 0.5, report.FillRatio)
+	require.Len(t, report.Fills, 1)
+	require.Equal(t, Cents(-450), report.PnL.Total.Realized)
+	require.Equal(t, fee, report.PnL.Total.Fees)
+
+	settlements, err := b.Exchange().GetSettlements(ctx, SettlementsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []Settlement{{MarketResult: "no", SettledTime: t0.Add(10 * time.Minute), Ticker: "KX-A", YesCount: 10, YesTotalCost: 450}}, settlements.Settlements)
+	orders, err := b.Exchange().GetOrders(ctx, OrdersRequest{Status: Resting})
+	require.NoError(t, err)
+	require.Empty(t, orders.Orders)
+}
+
+func TestBacktesterRunStrategy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	events := []MarketEvent{
+		testBookEvent("A", t0, OrderBookBids{{Price: 40, Quantity: 10}}, OrderBookBids{{Price: 55, Quantity: 5}}),
+		testBookEvent("A", t0.Add(time.Second), OrderBookBids{{Price: 40, Quantity: 10}, {Price: 41, Quantity: 3}}, OrderBookBids{{Price: 55, Quantity: 5}}),
+		// Sellers of Yes at 40 fill the 10 contracts ahead of the order and
+		// 2 of its 5.
+		TradeEvent(Trade{TradeID: "t1", Ticker: "A", TakerSide: No, YesPrice: 40, NoPrice: 60, Count: 12, CreatedTime: t0.Add(2 * time.Second)}),
+		testBookEvent("A", t0.Add(3*time.Second), OrderBookBids{{Price: 41, Quantity: 3}}, OrderBookBids{{Price: 55, Quantity: 5}}),
+	}
+
+	strategy, orderID := newBuyingStrategy(t)
+	var timers []time.Time
+	strategy.onTimer = func(ctx context.Context, r *Runner, now time.Time) error {
+		timers = append(timers, now)
+		return nil
+	}
+	b := NewBacktester(1000, FeeSchedule{}, Market{Ticker: "A"})
+	b.TimerInterval = 2 * time.Second
+	report, err := b.RunStrategy(ctx, events, strategy)
+	require.NoError(t, err)
+
+	// Timers fire on the clock of the events, and the order left open is
+	// canceled once they end.
+	require.Equal(t, []string{
+		"book [{$0.40 10}]",
+		"order resting",
+		"book [{$0.40 10} {$0.41 3}]",
+		"timer",
+		"trade t1",
+		"fill 2",
+		"order partially_filled",
+		"book [{$0.41 3}]",
+		"shutdown",
+	}, strategy.calls)
+	require.Equal(t, []time.Time{t0.Add(2 * time.Second)}, timers)
+	order, err := b.Exchange().GetOrder(ctx, *orderID)
+	require.NoError(t, err)
+	require.Equal(t, Canceled, order.Status)
+	require.Len(t, report.Fills, 1)
+	require.Equal(t, t0.Add(2*time.Second), report.Fills[0].CreatedTime)
+	require.Equal(t, 0.4, report.FillRatio)
+	require.Equal(t, Cents(1000-2*40), report.FinalBalance)
+}
+
+func TestReplayBooks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	recorder := NewFeedRecorder(&buf)
+	live := &Feed{c: testScriptedConn(start, time.Second)}
+	live.Record(recorder)
+	want := collectBooks(t, live)
+	require.NoError(t, recorder.Close())
+
+	replay, err := ReplayFeed(&buf, 0)
+	require.NoError(t, err)
+	events, err := ReplayBooks(context.Background(), replay, "A")
+	require.NoError(t, err)
+	require.Len(t, events, len(want))
+	for i, ev := range events {
+		require.Equal(t, want[i], ev.Book)
+		require.Equal(t, want[i].LoadedAt, ev.Time)
+	}
+}
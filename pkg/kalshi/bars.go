@@ -0,0 +1,337 @@
+package kalshi
+
+import (
+	"sort"
+	"time"
+)
+
+// BarKind is what closes a Bar.
+type BarKind int
+
+const (
+	// TimeBars span fixed intervals of time.
+	TimeBars BarKind = iota
+	// VolumeBars close once a number of contracts has traded.
+	VolumeBars
+	// TickBars close once a number of trades has been made.
+	TickBars
+)
+
+// Bar is a candlestick built locally by a BarAggregator.
+type Bar struct {
+	Ticker string
+	// Start and End are the bounds of the interval of time bars, End being
+	// exclusive. For other bars they are the times of the first and last
+	// trade or quote.
+	Start time.Time
+	End   time.Time
+	// Price is the traded Yes price. It is zero if TradeCount is zero.
+	Price OHLC
+	// VWAP is the volume-weighted average Yes price in cents.
+	VWAP       float64
+	Volume     int
+	TradeCount int
+	// YesBid and YesAsk are the best quotes seen in the book updates of
+	// the bar. They are zero if Quotes is zero or that side was empty.
+	YesBid OHLC
+	YesAsk OHLC
+	Quotes int
+}
+
+// ohlcState builds an OHLC from observations that may arrive out of order.
+type ohlcState struct {
+	OHLC
+	set     bool
+	openAt  time.Time
+	closeAt time.Time
+}
+
+func (s *ohlcState) add(price Cents, t time.Time) {
+	if !s.set {
+		s.OHLC = OHLC{Open: price, High: price, Low: price, Close: price}
+		s.set, s.openAt, s.closeAt = true, t, t
+		return
+	}
+	s.High = max(s.High, price)
+	s.Low = min(s.Low, price)
+	if t.Before(s.openAt) {
+		s.Open, s.openAt = price, t
+	}
+	if !t.Before(s.closeAt) {
+		s.Close, s.closeAt = price, t
+	}
+}
+
+type barState struct {
+	Bar
+	price, bid, ask ohlcState
+	notional        int64
+}
+
+func (b *barState) addTrade(price Cents, count int, t time.Time) {
+	b.price.add(price, t)
+	b.notional += int64(price) * int64(count)
+	b.Volume += count
+	b.TradeCount++
+}
+
+func (b *barState) addQuote(book *StreamOrderBook) {
+	if bid, ok := book.BestYesBid(); ok {
+		b.bid.add(bid, book.LoadedAt)
+	}
+	if ask, ok := book.BestYesAsk(); ok {
+		b.ask.add(ask, book.LoadedAt)
+	}
+	b.Quotes++
+}
+
+// extend widens the bounds of a volume or tick bar to include t.
+func (b *barState) extend(t time.Time) {
+	if b.Start.IsZero() || t.Before(b.Start) {
+		b.Start = t
+	}
+	if t.After(b.End) {
+		b.End = t
+	}
+}
+
+func (b *barState) bar() Bar {
+	bar := b.Bar
+	bar.Price, bar.YesBid, bar.YesAsk = b.price.OHLC, b.bid.OHLC, b.ask.OHLC
+	if b.Volume > 0 {
+		bar.VWAP = float64(b.notional) / float64(b.Volume)
+	}
+	return bar
+}
+
+// tickerBars are the bars of one market.
+type tickerBars struct {
+	// open are the open time bars by start, or the single open volume or
+	// tick bar at the zero time.
+	open map[time.Time]*barState
+	// watermark is the latest time seen.
+	watermark time.Time
+	// closedBefore is the time before which data belongs to bars already
+	// emitted and is dropped.
+	closedBefore time.Time
+	// seen holds the times of the trades added since closedBefore, by
+	// TradeID. Earlier trades are dropped anyway.
+	seen map[string]time.Time
+}
+
+// close moves closedBefore up to t, forgetting the trades before it.
+func (tb *tickerBars) close(t time.Time) {
+	if !t.After(tb.closedBefore) {
+		return
+	}
+	tb.closedBefore = t
+	for id, at := range tb.seen {
+		if at.Before(t) {
+			delete(tb.seen, id)
+		}
+	}
+}
+
+// BarAggregator builds bars from trades, e.g. from GetTrades, and order book
+// updates from a Feed, for any number of markets. It isn't safe for
+// concurrent use.
+//
+// Trades are deduplicated by TradeID, so overlapping sources may be
+// combined. A duplicate of a trade in a bar already emitted is dropped as
+// late. Data may arrive out of order: open, close and bounds follow
+// timestamps rather than arrival. Time bars are emitted once data at least
+// AllowedLateness past their end has arrived, and data for bars already
+// emitted is dropped and counted in Dropped. Volume and tick bars are
+// emitted as soon as they fill, in arrival order.
+type BarAggregator struct {
+	// AllowedLateness delays emitting time bars to wait for late data.
+	AllowedLateness time.Duration
+	// Dropped counts the trades and quotes that arrived too late.
+	Dropped int
+
+	kind    BarKind
+	size    int
+	period  time.Duration
+	tickers map[string]*tickerBars
+}
+
+// NewTimeBarAggregator creates a BarAggregator of bars spanning interval,
+// aligned to the Unix epoch. Intervals without trades or quotes have no
+// bar.
+func NewTimeBarAggregator(interval time.Duration) *BarAggregator {
+	return newBarAggregator(TimeBars, interval, 0)
+}
+
+// NewVolumeBarAggregator creates a BarAggregator of bars of volume
+// contracts, at least one. Trades crossing the boundary are split between
+// bars.
+func NewVolumeBarAggregator(volume int) *BarAggregator {
+	return newBarAggregator(VolumeBars, 0, max(volume, 1))
+}
+
+// NewTickBarAggregator creates a BarAggregator of bars of trades trades, at
+// least one.
+func NewTickBarAggregator(trades int) *BarAggregator {
+	return newBarAggregator(TickBars, 0, max(trades, 1))
+}
+
+func newBarAggregator(kind BarKind, period time.Duration, size int) *BarAggregator {
+	return &BarAggregator{
+		kind:    kind,
+		period:  period,
+		size:    size,
+		tickers: make(map[string]*tickerBars),
+	}
+}
+
+func (a *BarAggregator) bars(ticker string) *tickerBars {
+	tb, ok := a.tickers[ticker]
+	if !ok {
+		tb = &tickerBars{
+			open: make(map[time.Time]*barState),
+			seen: make(map[string]time.Time),
+		}
+		a.tickers[ticker] = tb
+	}
+	return tb
+}
+
+// bar returns the open bar for data of ticker at t, or nil if the data is
+// late.
+func (a *BarAggregator) bar(ticker string, tb *tickerBars, t time.Time) *barState {
+	if t.Before(tb.closedBefore) {
+		a.Dropped++
+		return nil
+	}
+	var start time.Time
+	if a.kind == TimeBars {
+		start = t.Truncate(a.period)
+	}
+	b, ok := tb.open[start]
+	if !ok {
+		b = &barState{Bar: Bar{Ticker: ticker}}
+		if a.kind == TimeBars {
+			b.Start, b.End = start, start.Add(a.period)
+		}
+		tb.open[start] = b
+	}
+	if a.kind != TimeBars {
+		b.extend(t)
+	}
+	return b
+}
+
+// AddTrade adds a trade and returns the bars it completed, oldest first.
+func (a *BarAggregator) AddTrade(trade Trade) []Bar {
+	tb := a.bars(trade.Ticker)
+	if trade.TradeID != "" && !trade.CreatedTime.Before(tb.closedBefore) {
+		if _, ok := tb.seen[trade.TradeID]; ok {
+			return nil
+		}
+		tb.seen[trade.TradeID] = trade.CreatedTime
+	}
+	if a.kind != VolumeBars {
+		b := a.bar(trade.Ticker, tb, trade.CreatedTime)
+		if b == nil {
+			return nil
+		}
+		b.addTrade(trade.YesPrice, trade.Count, trade.CreatedTime)
+		return a.advance(tb, trade.CreatedTime)
+	}
+
+	var done []Bar
+	for remaining := trade.Count; remaining > 0; {
+		b := a.bar(trade.Ticker, tb, trade.CreatedTime)
+		if b == nil {
+			return done
+		}
+		n := min(remaining, a.size-b.Volume)
+		b.addTrade(trade.YesPrice, n, trade.CreatedTime)
+		remaining -= n
+		done = append(done, a.advance(tb, trade.CreatedTime)...)
+	}
+	return done
+}
+
+// AddBook adds the quotes of an order book update, timed by its LoadedAt,
+// and returns the bars it completed, oldest first.
+func (a *BarAggregator) AddBook(book *StreamOrderBook) []Bar {
+	tb := a.bars(book.MarketID)
+	b := a.bar(book.MarketID, tb, book.LoadedAt)
+	if b == nil {
+		return nil
+	}
+	b.addQuote(book)
+	return a.advance(tb, book.LoadedAt)
+}
+
+// advance emits the bars of tb completed by data at t.
+func (a *BarAggregator) advance(tb *tickerBars, t time.Time) []Bar {
+	if t.After(tb.watermark) {
+		tb.watermark = t
+	}
+
+	switch a.kind {
+	case VolumeBars, TickBars:
+		b := tb.open[time.Time{}]
+		if (a.kind == VolumeBars && b.Volume < a.size) || (a.kind == TickBars && b.TradeCount < a.size) {
+			return nil
+		}
+		delete(tb.open, time.Time{})
+		tb.close(b.End)
+		return []Bar{b.bar()}
+	default:
+		return a.emit(tb, tb.watermark.Add(-a.AllowedLateness))
+	}
+}
+
+// emit removes and returns the time bars of tb ending at or before until,
+// oldest first.
+func (a *BarAggregator) emit(tb *tickerBars, until time.Time) []Bar {
+	var done []*barState
+	for start, b := range tb.open {
+		if !b.End.After(until) {
+			done = append(done, b)
+			delete(tb.open, start)
+		}
+	}
+	sort.Slice(done, func(i, j int) bool { return done[i].Start.Before(done[j].Start) })
+
+	bars := make([]Bar, len(done))
+	for i, b := range done {
+		bars[i] = b.bar()
+		tb.close(b.End)
+	}
+	return bars
+}
+
+// Flush returns the open bars of every market, sorted by ticker and start,
+// and closes them. Later data for their span is dropped.
+func (a *BarAggregator) Flush() []Bar {
+	tickers := make([]string, 0, len(a.tickers))
+	for ticker := range a.tickers {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	var bars []Bar
+	for _, ticker := range tickers {
+		tb := a.tickers[ticker]
+		if a.kind != TimeBars {
+			if b, ok := tb.open[time.Time{}]; ok {
+				delete(tb.open, time.Time{})
+				tb.close(b.End)
+				bars = append(bars, b.bar())
+			}
+			continue
+		}
+		var latest time.Time
+		for _, b := range tb.open {
+			if b.End.After(latest) {
+				latest = b.End
+			}
+		}
+		bars = append(bars, a.emit(tb, latest)...)
+	}
+	return bars
+}
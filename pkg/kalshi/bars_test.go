@@ -0,0 +1,140 @@
+package kalshi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testTrade(tradeID, ticker string, yesPrice Cents, count int, at time.Time) Trade {
+	return Trade{
+		TradeID:     tradeID,
+		Ticker:      ticker,
+		YesPrice:    yesPrice,
+		NoPrice:     100 - yesPrice,
+		Count:       count,
+		CreatedTime: at,
+		TakerSide:   Yes,
+	}
+}
+
+func TestTimeBars(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	a := NewTimeBarAggregator(time.Minute)
+	a.AllowedLateness = 10 * time.Second
+
+	require.Empty(t, a.AddTrade(testTrade("1", "A", 40, 10, t0.Add(5*time.Second))))
+	require.Empty(t, a.AddTrade(testTrade("2", "A", 50, 30, t0.Add(30*time.Second))))
+	// Out of order within the bar: doesn't become the close.
+	require.Empty(t, a.AddTrade(testTrade("3", "A", 35, 10, t0.Add(20*time.Second))))
+	// Duplicates, e.g. from GetTrades overlapping the feed, are ignored.
+	require.Empty(t, a.AddTrade(testTrade("3", "A", 35, 10, t0.Add(20*time.Second))))
+	require.Empty(t, a.AddBook(&StreamOrderBook{
+		MarketID: "A",
+		LoadedAt: t0.Add(40 * time.Second),
+		OrderBook: OrderBook{
+			YesBids: OrderBookBids{{Price: 48, Quantity: 5}},
+			NoBids:  OrderBookBids{{Price: 50, Quantity: 5}},
+		},
+	}))
+	// The next bar opens exactly on the boundary, but the first waits for
+	// late data.
+	require.Empty(t, a.AddTrade(testTrade("4", "A", 55, 1, t0.Add(time.Minute))))
+	require.Empty(t, a.AddTrade(testTrade("5", "A", 60, 1, t0.Add(50*time.Second))))
+
+	bars := a.AddTrade(testTrade("6", "A", 56, 1, t0.Add(time.Minute+10*time.Second)))
+	require.Equal(t, []Bar{{
+		Ticker:     "A",
+		Start:      t0,
+		End:        t0.Add(time.Minute),
+		Price:      OHLC{Open: 40, High: 60, Low: 35, Close: 60},
+		VWAP:       float64(40*10+50*30+35*10+60*1) / 51,
+		Volume:     51,
+		TradeCount: 4,
+		YesBid:     OHLC{Open: 48, High: 48, Low: 48, Close: 48},
+		YesAsk:     OHLC{Open: 50, High: 50, Low: 50, Close: 50},
+		Quotes:     1,
+	}}, bars)
+
+	// Data for an emitted bar is dropped.
+	require.Empty(t, a.AddTrade(testTrade("7", "A", 10, 1, t0.Add(59*time.Second))))
+	require.Equal(t, 1, a.Dropped)
+
+	// Markets are aggregated separately; skipped intervals have no bars.
+	require.Empty(t, a.AddTrade(testTrade("8", "B", 20, 2, t0.Add(3*time.Minute))))
+	bars = a.Flush()
+	require.Len(t, bars, 2)
+	require.Equal(t, "A", bars[0].Ticker)
+	require.Equal(t, t0.Add(time.Minute), bars[0].Start)
+	require.Equal(t, OHLC{Open: 55, High: 56, Low: 55, Close: 56}, bars[0].Price)
+	require.Equal(t, "B", bars[1].Ticker)
+	require.Equal(t, t0.Add(3*time.Minute), bars[1].Start)
+	require.Empty(t, a.Flush())
+}
+
+func TestVolumeBars(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	a := NewVolumeBarAggregator(10)
+
+	require.Empty(t, a.AddTrade(testTrade("1", "A", 40, 6, t0)))
+	// Splits across two bars and fills the second.
+	bars := a.AddTrade(testTrade("2", "A", 50, 14, t0.Add(time.Second)))
+	require.Len(t, bars, 2)
+	require.Equal(t, Bar{
+		Ticker:     "A",
+		Start:      t0,
+		End:        t0.Add(time.Second),
+		Price:      OHLC{Open: 40, High: 50, Low: 40, Close: 50},
+		VWAP:       float64(40*6+50*4) / 10,
+		Volume:     10,
+		TradeCount: 2,
+	}, bars[0])
+	require.Equal(t, 10, bars[1].Volume)
+	require.Equal(t, 50.0, bars[1].VWAP)
+	require.Equal(t, t0.Add(time.Second), bars[1].Start)
+
+	// Trades older than the last bar are late.
+	require.Empty(t, a.AddTrade(testTrade("3", "A", 45, 1, t0)))
+	require.Equal(t, 1, a.Dropped)
+
+	require.Empty(t, a.AddTrade(testTrade("4", "A", 45, 3, t0.Add(2*time.Second))))
+	bars = a.Flush()
+	require.Len(t, bars, 1)
+	require.Equal(t, 3, bars[0].Volume)
+}
+
+func TestTickBars(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	a := NewTickBarAggregator(2)
+
+	require.Empty(t, a.AddTrade(testTrade("1", "A", 40, 100, t0)))
+	bars := a.AddTrade(testTrade("2", "A", 42, 1, t0.Add(time.Second)))
+	require.Len(t, bars, 1)
+	require.Equal(t, 2, bars[0].TradeCount)
+	require.Equal(t, 101, bars[0].Volume)
+	require.Equal(t, OHLC{Open: 40, High: 42, Low: 40, Close: 42}, bars[0].Price)
+}
+
+func TestBarsForgetEmittedTrades(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	a := NewTimeBarAggregator(time.Minute)
+	require.Empty(t, a.AddTrade(testTrade("1", "A", 40, 10, t0.Add(5*time.Second))))
+	require.Len(t, a.AddTrade(testTrade("2", "A", 50, 10, t0.Add(65*time.Second))), 1)
+
+	// Only the trades of open bars are remembered. A duplicate of an
+	// emitted trade is late.
+	require.Equal(t, map[string]time.Time{"2": t0.Add(65 * time.Second)}, a.tickers["A"].seen)
+	require.Empty(t, a.AddTrade(testTrade("1", "A", 40, 10, t0.Add(5*time.Second))))
+	require.Equal(t, 1, a.Dropped)
+	require.Empty(t, a.AddTrade(testTrade("2", "A", 50, 10, t0.Add(65*time.Second))))
+	require.Equal(t, 1, a.Dropped)
+}
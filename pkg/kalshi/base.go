@@ -15,13 +15,17 @@ type KalshiClientLogic interface {
 	Markets(ctx context.Context, req MarketsRequest) (*MarketsResponse, error)
 	MarketOrderBook(ctx context.Context, ticker string) (*OrderBook, error)
 	MarketHistory(ctx context.Context, ticker string, req MarketHistoryRequest) (*MarketHistoryResponse, error)
+	MarketCandlesticks(ctx context.Context, seriesTicker, ticker string, req MarketCandlesticksRequest) (*MarketCandlesticksResponse, error)
 	Series(ctx context.Context, seriesTicker string) (*Series, error)
-	Trades(ctx context.Context, req TradesRequest) (*TradesResponse, error)
+	GetTrades(ctx context.Context, req TradesRequest) (*TradesResponse, error)
 
 	// orders
 	CreateOrder(ctx context.Context, req CreateOrderRequest) (*Order, error)
 	CancelOrder(ctx context.Context, orderID string) (*Order, error)
+	BatchCreateOrders(ctx context.Context, reqs []CreateOrderRequest) ([]BatchOrderResult, error)
+	BatchCancelOrders(ctx context.Context, orderIDs []string) ([]BatchOrderResult, error)
 	DecreaseOrder(ctx context.Context, orderID string, req DecreaseOrderRequest) (*Order, error)
+	AmendOrder(ctx context.Context, orderID string, req AmendOrderRequest) (*AmendOrderResponse, error)
 	GetOrder(ctx context.Context, orderID string) (*Order, error)
 	GetOrders(ctx context.Context, req OrdersRequest) (*OrdersResponse, error)
 	GetBalance(ctx context.Context) (Cents, error)
@@ -29,3 +33,5 @@ type KalshiClientLogic interface {
 	GetPositions(ctx context.Context, req PositionsRequest) (*PositionsResponse, error)
 	GetSettlements(ctx context.Context, req SettlementsRequest) (*SettlementsResponse, error)
 }
+
+var _ KalshiClientLogic = (*Client)(nil)
@@ -0,0 +1,277 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxBatchSize is the maximum number of orders per batched request.
+	maxBatchSize = 20
+	// batchFallbackConcurrency bounds the individual calls made when the
+	// batched endpoints are unavailable.
+	batchFallbackConcurrency = 5
+	// rateLimitBackoff is how long fanned out calls wait before retrying
+	// after ErrRateLimitExceeded.
+	rateLimitBackoff = 50 * time.Millisecond
+)
+
+// BatchOrderResult is the outcome of a single order in a batch.
+type BatchOrderResult struct {
+	// ClientOrderID is set for created orders.
+	ClientOrderID string
+	// OrderID is set for canceled orders, and for created orders that
+	// were placed.
+	OrderID string
+	Order   *Order
+	// ReducedBy is the number of contracts canceled. It is only reported
+	// by the batched endpoint.
+	ReducedBy int
+	// Err is non-nil if the order failed. Errors reported by the API are
+	// of type *APIError.
+	Err error
+}
+
+type batchCreateOrdersRequest struct {
+	Orders []CreateOrderRequest `json:"orders"`
+}
+
+type batchCreateOrdersResponse struct {
+	Orders []struct {
+		ClientOrderID string    `json:"client_order_id"`
+		Order         *Order    `json:"order"`
+		Error         *APIError `json:"error"`
+	} `json:"orders"`
+}
+
+type batchCancelOrdersRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type batchCancelOrdersResponse struct {
+	Orders []struct {
+		OrderID   string    `json:"order_id"`
+		Order     *Order    `json:"order"`
+		ReducedBy int       `json:"reduced_by"`
+		Error     *APIError `json:"error"`
+	} `json:"orders"`
+}
+
+// BatchCreateOrders is described here:
+// https://trading-api.readme.io/reference/batchcreateorders.
+//
+// Orders are sent in batches of up to 20 and results are returned in the
+// order of reqs. Orders without a ClientOrderID are given one unique to the
+// call, which is kept when they are placed individually; to retry a call
+// without placing its orders twice, give them ClientOrderIDs. If the
+// batched endpoint is unavailable, the remaining orders are placed with
+// individual CreateOrder calls. If a batch fails, the results of the preceding
+// batches are returned along with the error.
+func (c *Client) BatchCreateOrders(ctx context.Context, reqs []CreateOrderRequest) ([]BatchOrderResult, error) {
+	reqs = append([]CreateOrderRequest(nil), reqs...)
+	nonce := uuid.NewString()
+	for i := range reqs {
+		if reqs[i].ClientOrderID == "" {
+			reqs[i].ClientOrderID = batchClientOrderID(nonce, i)
+		}
+	}
+
+	results := make([]BatchOrderResult, 0, len(reqs))
+	for start := 0; start < len(reqs); start += maxBatchSize {
+		batch := reqs[start:min(start+maxBatchSize, len(reqs))]
+
+		var resp batchCreateOrdersResponse
+		err := c.request(ctx, request{
+			Name:         "BatchCreateOrders",
+			Method:       "POST",
+			Endpoint:     "portfolio/orders/batched",
+			JSONRequest:  batchCreateOrdersRequest{Orders: batch},
+			JSONResponse: &resp,
+		}, authenticated)
+		if isBatchUnavailable(err) {
+			return append(results, FanOutCreateOrders(ctx, c, reqs[start:], batchFallbackConcurrency)...), nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("c.request: %w", err)
+		}
+		if len(resp.Orders) != len(batch) {
+			return results, fmt.Errorf("got %d results for %d orders", len(resp.Orders), len(batch))
+		}
+
+		for i, r := range resp.Orders {
+			result := BatchOrderResult{
+				ClientOrderID: batch[i].ClientOrderID,
+				Order:         r.Order,
+			}
+			if r.Order != nil {
+				result.OrderID = r.Order.OrderID
+			}
+			if r.Error != nil {
+				result.Err = r.Error
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// BatchCancelOrders is described here:
+// https://trading-api.readme.io/reference/batchcancelorders.
+//
+// It batches and falls back to individual CancelOrder calls in the same way
+// as BatchCreateOrders.
+func (c *Client) BatchCancelOrders(ctx context.Context, orderIDs []string) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orderIDs))
+	for start := 0; start < len(orderIDs); start += maxBatchSize {
+		batch := orderIDs[start:min(start+maxBatchSize, len(orderIDs))]
+
+		var resp batchCancelOrdersResponse
+		err := c.request(ctx, request{
+			Name:         "BatchCancelOrders",
+			Method:       "DELETE",
+			Endpoint:     "portfolio/orders/batched",
+			JSONRequest:  batchCancelOrdersRequest{IDs: batch},
+			JSONResponse: &resp,
+		}, authenticated)
+		if isBatchUnavailable(err) {
+			return append(results, FanOutCancelOrders(ctx, c, orderIDs[start:], batchFallbackConcurrency)...), nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("c.request: %w", err)
+		}
+		if len(resp.Orders) != len(batch) {
+			return results, fmt.Errorf("got %d results for %d orders", len(resp.Orders), len(batch))
+		}
+
+		for i, r := range resp.Orders {
+			result := BatchOrderResult{
+				OrderID:   batch[i],
+				Order:     r.Order,
+				ReducedBy: r.ReducedBy,
+			}
+			if r.Error != nil {
+				result.Err = r.Error
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// isBatchUnavailable reports whether err means the batched endpoints don't
+// exist for the client's environment. A 403 is not included: it means the
+// request was refused, and the individual calls would be refused too.
+func isBatchUnavailable(err error) bool {
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	switch httpErr.Code {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// FanOutCreateOrders places each of reqs with its own CreateOrder call, at
+// most concurrency at a time. Calls rejected by the client-side rate limiter
+// are retried until ctx is done. Results are returned in the order of reqs.
+// ClientOrderIDs are generated as by BatchCreateOrders, and kept when calls
+// are retried.
+func FanOutCreateOrders(ctx context.Context, client KalshiClientLogic, reqs []CreateOrderRequest, concurrency int) []BatchOrderResult {
+	results := make([]BatchOrderResult, len(reqs))
+	nonce := uuid.NewString()
+	fanOut(ctx, len(reqs), concurrency, func(ctx context.Context, i int) {
+		req := reqs[i]
+		if req.ClientOrderID == "" {
+			req.ClientOrderID = batchClientOrderID(nonce, i)
+		}
+		var order *Order
+		err := retryRateLimited(ctx, func(ctx context.Context) (err error) {
+			order, err = client.CreateOrder(ctx, req)
+			return err
+		})
+		results[i] = BatchOrderResult{
+			ClientOrderID: req.ClientOrderID,
+			Order:         order,
+			Err:           err,
+		}
+		if order != nil {
+			results[i].OrderID = order.OrderID
+		}
+	})
+	return results
+}
+
+// batchClientOrderID derives the ClientOrderID of the i-th order of the call
+// identified by nonce.
+func batchClientOrderID(nonce string, i int) string {
+	return DeterministicClientOrderID("batch", nonce, strconv.Itoa(i))
+}
+
+// FanOutCancelOrders cancels each of orderIDs with its own CancelOrder call,
+// in the same way as FanOutCreateOrders.
+func FanOutCancelOrders(ctx context.Context, client KalshiClientLogic, orderIDs []string, concurrency int) []BatchOrderResult {
+	results := make([]BatchOrderResult, len(orderIDs))
+	fanOut(ctx, len(orderIDs), concurrency, func(ctx context.Context, i int) {
+		var order *Order
+		err := retryRateLimited(ctx, func(ctx context.Context) (err error) {
+			order, err = client.CancelOrder(ctx, orderIDs[i])
+			return err
+		})
+		results[i] = BatchOrderResult{
+			OrderID: orderIDs[i],
+			Order:   order,
+			Err:     err,
+		}
+	})
+	return results
+}
+
+// fanOut calls fn for every index in [0, n), at most concurrency at a time,
+// and waits for all calls to return.
+func fanOut(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// retryRateLimited calls fn until it returns something other than
+// ErrRateLimitExceeded or ctx is done. Each call's context is marked with its
+// attempt, counting from the attempt ctx is marked with.
+func retryRateLimited(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := attemptFrom(ctx); ; attempt++ {
+		err := fn(WithAttempt(ctx, attempt))
+		if !errors.Is(err, ErrRateLimitExceeded) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(rateLimitBackoff):
+		}
+	}
+}
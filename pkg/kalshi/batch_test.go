@@ -0,0 +1,180 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCreateOrders(t *testing.T) {
+	t.Parallel()
+
+	var batches atomic.Int32
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/portfolio/orders/batched", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NotEmpty(t, r.Header.Get(HeaderAccessSignature))
+		batches.Add(1)
+
+		var req batchCreateOrdersRequest
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+			writeJSON(t, w, http.StatusBadRequest, map[string]any{})
+			return
+		}
+		assert.LessOrEqual(t, len(req.Orders), maxBatchSize)
+
+		var orders []map[string]any
+		for _, o := range req.Orders {
+			assert.NotEmpty(t, o.ClientOrderID)
+			if o.Count > 5 {
+				orders = append(orders, map[string]any{
+					"client_order_id": o.ClientOrderID,
+					"error": map[string]any{
+						"code":    "insufficient_balance",
+						"message": "insufficient balance",
+					},
+				})
+				continue
+			}
+			orders = append(orders, map[string]any{
+				"client_order_id": o.ClientOrderID,
+				"order": map[string]any{
+					"order_id":        "id-" + o.ClientOrderID,
+					"client_order_id": o.ClientOrderID,
+					"status":          "resting",
+				},
+			})
+		}
+		writeJSON(t, w, http.StatusCreated, map[string]any{"orders": orders})
+	}))
+
+	var reqs []CreateOrderRequest
+	for i := 0; i < 25; i++ {
+		reqs = append(reqs, CreateOrderRequest{
+			ClientOrderID: fmt.Sprint(i),
+			Count:         i % 10,
+		})
+	}
+
+	results, err := client.BatchCreateOrders(context.Background(), reqs)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, batches.Load())
+	require.Len(t, results, len(reqs))
+	for i, r := range results {
+		require.Equal(t, fmt.Sprint(i), r.ClientOrderID)
+		if reqs[i].Count > 5 {
+			var apiErr *APIError
+			require.ErrorAs(t, r.Err, &apiErr)
+			require.Equal(t, "insufficient_balance", apiErr.Code)
+			require.Nil(t, r.Order)
+			continue
+		}
+		require.NoError(t, r.Err)
+		require.Equal(t, "id-"+fmt.Sprint(i), r.OrderID)
+		require.Equal(t, Resting, r.Order.Status)
+	}
+}
+
+func TestBatchCancelOrdersFallback(t *testing.T) {
+	t.Parallel()
+
+	var canceled atomic.Int32
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		if r.URL.Path == "/portfolio/orders/batched" {
+			writeJSON(t, w, http.StatusNotFound, map[string]any{})
+			return
+		}
+		orderID := r.URL.Path[len("/portfolio/orders/"):]
+		if orderID == "missing" {
+			writeJSON(t, w, http.StatusNotFound, map[string]any{})
+			return
+		}
+		canceled.Add(1)
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"order": map[string]any{"order_id": orderID, "status": "canceled"},
+		})
+	}))
+
+	ids := []string{"a", "missing", "b", "c"}
+	results, err := client.BatchCancelOrders(context.Background(), ids)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, canceled.Load())
+	require.Len(t, results, len(ids))
+	for i, r := range results {
+		require.Equal(t, ids[i], r.OrderID)
+		if r.OrderID == "missing" {
+			require.Error(t, r.Err)
+			continue
+		}
+		require.NoError(t, r.Err)
+		require.Equal(t, Canceled, r.Order.Status)
+	}
+}
+
+func TestBatchCancelOrdersForbidden(t *testing.T) {
+	t.Parallel()
+
+	// A 403 is an authorization problem, not a missing endpoint, so it
+	// doesn't fall back to individual calls.
+	var calls atomic.Int32
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(t, w, http.StatusForbidden, map[string]any{})
+	}))
+
+	_, err := client.BatchCancelOrders(context.Background(), []string{"a", "b"})
+	var httpErr *HttpError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusForbidden, httpErr.Code)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestFanOutCreateOrdersRateLimited(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient()
+	limited := &rateLimitedClient{KalshiClientLogic: client}
+	limited.reject.Store(3)
+
+	var reqs []CreateOrderRequest
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, CreateOrderRequest{Ticker: "A", Count: 1, Side: Yes})
+	}
+	results := FanOutCreateOrders(context.Background(), limited, reqs, 3)
+	require.Len(t, results, len(reqs))
+	ids := map[string]bool{}
+	for _, r := range results {
+		require.NoError(t, r.Err)
+		require.NotEmpty(t, r.OrderID)
+		ids[r.ClientOrderID] = true
+	}
+	// Generated IDs are unique within the call and kept on retry.
+	require.Len(t, ids, len(reqs))
+	require.Equal(t, 10, client.creates)
+
+	// Placing the same orders again isn't rejected as a duplicate.
+	for _, r := range FanOutCreateOrders(context.Background(), limited, reqs, 3) {
+		require.NoError(t, r.Err)
+		require.False(t, ids[r.ClientOrderID])
+	}
+}
+
+// rateLimitedClient rejects the first reject calls to CreateOrder.
+type rateLimitedClient struct {
+	KalshiClientLogic
+	reject atomic.Int32
+}
+
+func (c *rateLimitedClient) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	if c.reject.Add(-1) >= 0 {
+		return nil, ErrRateLimitExceeded
+	}
+	return c.KalshiClientLogic.CreateOrder(ctx, req)
+}
@@ -0,0 +1,176 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// cancelAllConcurrency bounds the CancelOrder calls made by CancelAll.
+	cancelAllConcurrency = 5
+	// cancelAllAttempts is the number of times CancelAll tries each order.
+	cancelAllAttempts = 3
+	// cancelAllRetryDelay is the pause between attempts.
+	cancelAllRetryDelay = 100 * time.Millisecond
+	// minDeadMansSwitchInterval bounds how often a DeadMansSwitch checks
+	// for heartbeats.
+	minDeadMansSwitchInterval = time.Millisecond
+)
+
+// CancelAllFilter selects the resting orders canceled by CancelAll. The zero
+// value selects every resting order.
+type CancelAllFilter struct {
+	Ticker      string
+	EventTicker string
+}
+
+// CancelAllReport is the outcome of CancelAll.
+type CancelAllReport struct {
+	// Found is the number of resting orders that matched the filter.
+	Found    int
+	Canceled []Order
+	// Failed maps the IDs of orders that couldn't be canceled to the last
+	// error encountered.
+	Failed map[string]error
+}
+
+// CancelAllOrders cancels every resting order matching filter. See CancelAll.
+func (c *Client) CancelAllOrders(ctx context.Context, filter CancelAllFilter) (*CancelAllReport, error) {
+	return CancelAll(ctx, c, filter)
+}
+
+// CancelAll lists the resting orders matching filter with GetOrders and
+// cancels them, a few at a time. Calls rejected by the client-side rate
+// limiter are retried until ctx is done; other failures are retried a few
+// times unless the API rejects the cancellation outright.
+//
+// An error is only returned if the orders can't be listed. Orders that
+// couldn't be canceled are reported in CancelAllReport.Failed.
+func CancelAll(ctx context.Context, client KalshiClientLogic, filter CancelAllFilter) (*CancelAllReport, error) {
+	var orderIDs []string
+	req := OrdersRequest{
+		Ticker:      filter.Ticker,
+		EventTicker: filter.EventTicker,
+		Status:      Resting,
+	}
+	for {
+		resp, err := client.GetOrders(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("client.GetOrders: %w", err)
+		}
+		for _, order := range resp.Orders {
+			orderIDs = append(orderIDs, order.OrderID)
+		}
+		if resp.Cursor == "" {
+			break
+		}
+		req.Cursor = resp.Cursor
+	}
+
+	var (
+		mu     sync.Mutex
+		report = &CancelAllReport{
+			Found:  len(orderIDs),
+			Failed: make(map[string]error),
+		}
+	)
+	fanOut(ctx, len(orderIDs), cancelAllConcurrency, func(ctx context.Context, i int) {
+		order, err := cancelWithRetry(ctx, client, orderIDs[i])
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			report.Failed[orderIDs[i]] = err
+			return
+		}
+		report.Canceled = append(report.Canceled, *order)
+	})
+	return report, nil
+}
+
+func cancelWithRetry(ctx context.Context, client KalshiClientLogic, orderID string) (*Order, error) {
+	var err error
+	for attempt := 1; attempt <= cancelAllAttempts; attempt++ {
+		var order *Order
+		err = retryRateLimited(WithAttempt(ctx, attempt), func(ctx context.Context) (err error) {
+			order, err = client.CancelOrder(ctx, orderID)
+			return err
+		})
+		if err == nil {
+			return order, nil
+		}
+
+		var httpErr *HttpError
+		if errors.As(err, &httpErr) && !httpErr.IsRetryable() {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(cancelAllRetryDelay):
+		}
+	}
+	return nil, err
+}
+
+// DeadMansSwitch cancels resting orders when it stops receiving heartbeats,
+// e.g. because the goroutine driving a strategy is stuck.
+type DeadMansSwitch struct {
+	client  KalshiClientLogic
+	timeout time.Duration
+	filter  CancelAllFilter
+
+	mu       sync.Mutex
+	lastBeat time.Time
+}
+
+// NewDeadMansSwitch creates a DeadMansSwitch that cancels the orders matching
+// filter once timeout passes without a heartbeat. The timer starts when
+// NewDeadMansSwitch is called. timeout must be positive.
+func NewDeadMansSwitch(client KalshiClientLogic, timeout time.Duration, filter CancelAllFilter) (*DeadMansSwitch, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("timeout is %v, must be positive", timeout)
+	}
+	return &DeadMansSwitch{
+		client:   client,
+		timeout:  timeout,
+		filter:   filter,
+		lastBeat: time.Now(),
+	}, nil
+}
+
+// Heartbeat resets the timer.
+func (d *DeadMansSwitch) Heartbeat() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastBeat = time.Now()
+}
+
+func (d *DeadMansSwitch) sinceLastBeat() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return time.Since(d.lastBeat)
+}
+
+// Run waits until either ctx is done or the timeout passes without a
+// heartbeat. In the latter case it cancels the matching orders and returns
+// the report.
+func (d *DeadMansSwitch) Run(ctx context.Context) (*CancelAllReport, error) {
+	ticker := time.NewTicker(max(d.timeout/4, minDeadMansSwitchInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			if d.sinceLastBeat() < d.timeout {
+				continue
+			}
+			return CancelAll(ctx, d.client, d.filter)
+		}
+	}
+}
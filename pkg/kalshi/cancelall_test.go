@@ -0,0 +1,90 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelAll(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newFakeClient()
+
+	var ids []string
+	for _, ticker := range []string{"A", "A", "A", "B"} {
+		order, err := client.CreateOrder(ctx, CreateOrderRequest{Ticker: ticker, Count: 1, Side: Yes})
+		require.NoError(t, err)
+		ids = append(ids, order.OrderID)
+	}
+	// Already executed orders aren't touched.
+	client.fill(ids[0], 1)
+	client.cancelErrs[ids[1]] = NewHttpError(http.StatusBadRequest, "nope")
+
+	report, err := CancelAll(ctx, client, CancelAllFilter{Ticker: "A"})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Found)
+	require.Len(t, report.Canceled, 1)
+	require.Equal(t, ids[2], report.Canceled[0].OrderID)
+	require.Contains(t, report.Failed, ids[1])
+
+	order, err := client.GetOrder(ctx, ids[3])
+	require.NoError(t, err)
+	require.Equal(t, Resting, order.Status)
+
+	client.ordersErr = errors.New("down")
+	_, err = CancelAll(ctx, client, CancelAllFilter{})
+	require.Error(t, err)
+}
+
+func TestDeadMansSwitch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := newFakeClient()
+	order, err := client.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Count: 1, Side: Yes})
+	require.NoError(t, err)
+
+	_, err = NewDeadMansSwitch(client, 0, CancelAllFilter{})
+	require.Error(t, err)
+	d, err := NewDeadMansSwitch(client, 100*time.Millisecond, CancelAllFilter{})
+	require.NoError(t, err)
+
+	done := make(chan *CancelAllReport)
+	errs := make(chan error, 1)
+	go func() {
+		report, err := d.Run(ctx)
+		errs <- err
+		done <- report
+	}()
+
+	// Heartbeats keep the switch from triggering.
+	stopBeats := time.After(300 * time.Millisecond)
+beats:
+	for {
+		select {
+		case <-done:
+			t.Fatal("triggered while heartbeating")
+		case <-stopBeats:
+			break beats
+		case <-time.After(20 * time.Millisecond):
+			d.Heartbeat()
+		}
+	}
+
+	report := <-done
+	require.NoError(t, <-errs)
+	require.Equal(t, 1, report.Found)
+	require.Len(t, report.Canceled, 1)
+
+	order, err = client.GetOrder(ctx, order.OrderID)
+	require.NoError(t, err)
+	require.Equal(t, Canceled, order.Status)
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -38,6 +40,9 @@ type Client struct {
 
 	httpClient    *http.Client
 	requestSigner *KeySigner
+	middleware    []Middleware
+	feedObservers []FeedObserver
+	logger        *slog.Logger
 }
 
 type CursorResponse struct {
@@ -51,20 +56,27 @@ type CursorRequest struct {
 
 type request struct {
 	CursorRequest
+	// Name, Ticker and OrderID identify the request to middleware.
+	Name         string
+	Ticker       string
+	OrderID      string
 	Method       string
 	Endpoint     string
 	QueryParams  any
 	JSONRequest  any
 	JSONResponse any
+	// NotFound is the error a 404 without an error code matches.
+	NotFound error
 }
 
 func (c *Client) jsonRequestHeaders(
 	ctx context.Context,
 	headers http.Header,
-	method string, reqURL string,
+	info RequestInfo, reqURL string,
 	jsonReq any, jsonResp any,
 	auth bool,
-) error {
+) (err error) {
+	method := info.Method
 	// get body if non-nil
 	var body io.Reader = nil
 	if jsonReq != nil {
@@ -95,11 +107,26 @@ func (c *Client) jsonRequestHeaders(
 		}
 	}
 
+	start := time.Now()
+	var statusCode, ran int
+	defer func() {
+		c.afterResponse(ctx, req, ran, ResponseInfo{
+			RequestInfo: info,
+			StatusCode:  statusCode,
+			Latency:     time.Since(start),
+			Err:         err,
+		})
+	}()
+	if req, ran, err = c.beforeRequest(req, info); err != nil {
+		return fmt.Errorf("c.beforeRequest: %w", err)
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("c.httpClient.Do: %w", err)
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
 	respBodyByt, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -107,7 +134,7 @@ func (c *Client) jsonRequestHeaders(
 	}
 
 	if resp.StatusCode >= 400 {
-		return NewHttpError(resp.StatusCode, string(respBodyByt))
+		return newHttpErrorFromResponse(resp, respBodyByt)
 	}
 
 	if c.httpClient.Jar != nil {
@@ -143,19 +170,37 @@ func (c *Client) request(
 		u.RawQuery = v.Encode()
 	}
 
+	info := RequestInfo{
+		Name:     r.Name,
+		Method:   r.Method,
+		Endpoint: r.Endpoint,
+		Ticker:   r.Ticker,
+		OrderID:  r.OrderID,
+		Attempt:  attemptFrom(ctx),
+	}
+
 	// Do not block via Wait! Trades have to be
 	// fast to be meaningful!
 	if !c.RateLimit.Allow() {
+		req, err := http.NewRequestWithContext(ctx, r.Method, u.String(), nil)
+		if err != nil {
+			return fmt.Errorf("http.NewRequestWithContext: %w", err)
+		}
+		c.afterResponse(ctx, req, len(c.middleware), ResponseInfo{RequestInfo: info, Err: ErrRateLimitExceeded})
 		return ErrRateLimitExceeded
 	}
 
 	if err := c.jsonRequestHeaders(
 		ctx,
 		nil,
-		r.Method,
+		info,
 		u.String(), r.JSONRequest, r.JSONResponse,
 		auth,
 	); err != nil {
+		var httpErr *HttpError
+		if errors.As(err, &httpErr) && httpErr.Code == http.StatusNotFound {
+			httpErr.notFound = r.NotFound
+		}
 		return fmt.Errorf("jsonRequestHeaders: %w", err)
 	}
 
@@ -182,6 +227,16 @@ func (t Timestamp) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.Itoa(int(time.Time(t).UTC().Unix()))), nil
 }
 
+// EncodeValues implements query.Encoder so that Timestamps are sent as
+// POSIX seconds in query strings. Zero Timestamps are omitted.
+func (t Timestamp) EncodeValues(key string, v *url.Values) error {
+	if time.Time(t).IsZero() {
+		return nil
+	}
+	v.Set(key, strconv.FormatInt(time.Time(t).Unix(), 10))
+	return nil
+}
+
 func newRateLimit(rps int) *rate.Limiter {
 	return rate.NewLimiter(rate.Every(time.Second/time.Duration(rps)), rps)
 }
@@ -1,7 +1,19 @@
 package kalshi
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ggarcia209/kalshi/config"
 	"github.com/spf13/viper"
@@ -22,3 +34,337 @@ func testClient(t *testing.T) *Client {
 
 	return c
 }
+
+var (
+	testKeyOnce sync.Once
+	testKeyPEM  string
+)
+
+// testServerClient returns a Client whose requests are served by handler.
+func testServerClient(t *testing.T, handler http.Handler) *Client {
+	testKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		testKeyPEM = string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}))
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL+"/", "test-key-id", "", testKeyPEM, false, 1000)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+// writeJSON writes v as the response to a test request.
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("json.Encode: %v", err)
+	}
+}
+
+// errFakeOrderNotFound is the error the client returns for a missing order.
+func errFakeOrderNotFound() error {
+	err := NewHttpError(http.StatusNotFound, "not found")
+	err.notFound = ErrOrderNotFound
+	return err
+}
+
+// fakeClient is an in-memory exchange for testing code built on top of
+// KalshiClientLogic. Methods it doesn't implement panic.
+type fakeClient struct {
+	KalshiClientLogic
+
+	mu     sync.Mutex
+	nextID int
+	orders map[string]*Order // by OrderID
+	fills  []Fill
+
+	// createErr, if set, is returned by CreateOrder after the order is
+	// placed, simulating a response lost in transit.
+	createErr error
+	// rejectErr, if set, is returned by CreateOrder instead of placing the
+	// order.
+	rejectErr error
+	// ordersErr, if set, is returned by GetOrders.
+	ordersErr error
+	// cancelErrs makes CancelOrder fail for an order ID until the
+	// error is removed.
+	cancelErrs map[string]error
+	creates    int
+
+	positions []MarketPosition
+	books     map[string]*OrderBook
+	markets   map[string]*Market
+	trades    []Trade
+
+	settlements []Settlement
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		orders:     make(map[string]*Order),
+		cancelErrs: make(map[string]error),
+		books:      make(map[string]*OrderBook),
+		markets:    make(map[string]*Market),
+	}
+}
+
+func (f *fakeClient) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.creates++
+	if f.rejectErr != nil {
+		return nil, f.rejectErr
+	}
+	for _, order := range f.orders {
+		if req.ClientOrderID != "" && order.ClientOrderID == req.ClientOrderID {
+			return nil, NewHttpError(http.StatusConflict, "duplicate client order id")
+		}
+	}
+
+	f.nextID++
+	order := &Order{
+		Action:         req.Action,
+		ClientOrderID:  req.ClientOrderID,
+		OrderID:        fmt.Sprintf("order-%d", f.nextID),
+		PlaceCount:     req.Count,
+		RemainingCount: req.Count,
+		Side:           req.Side,
+		Status:         Resting,
+		Ticker:         req.Ticker,
+		Type:           req.Type,
+		YesPrice:       req.YesPrice,
+		NoPrice:        req.NoPrice,
+	}
+	f.orders[order.OrderID] = order
+
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	o := *order
+	return &o, nil
+}
+
+func (f *fakeClient) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, errFakeOrderNotFound()
+	}
+	o := *order
+	return &o, nil
+}
+
+func (f *fakeClient) GetOrders(ctx context.Context, req OrdersRequest) (*OrdersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.ordersErr != nil {
+		return nil, f.ordersErr
+	}
+	resp := &OrdersResponse{}
+	for _, order := range f.orders {
+		if req.Ticker != "" && order.Ticker != req.Ticker {
+			continue
+		}
+		if req.Status != "" && order.Status != req.Status {
+			continue
+		}
+		resp.Orders = append(resp.Orders, *order)
+	}
+	sort.Slice(resp.Orders, func(i, j int) bool {
+		return resp.Orders[i].OrderID < resp.Orders[j].OrderID
+	})
+	return resp, nil
+}
+
+func (f *fakeClient) CancelOrder(ctx context.Context, orderID string) (*Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.cancelErrs[orderID]; err != nil {
+		return nil, err
+	}
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, errFakeOrderNotFound()
+	}
+	order.Status = Canceled
+	order.RemainingCount = 0
+	o := *order
+	return &o, nil
+}
+
+func (f *fakeClient) DecreaseOrder(ctx context.Context, orderID string, req DecreaseOrderRequest) (*Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, errFakeOrderNotFound()
+	}
+	reduceBy := req.ReduceBy
+	if req.ReduceTo > 0 {
+		reduceBy = order.RemainingCount - req.ReduceTo
+	}
+	order.DecreaseCount += reduceBy
+	order.RemainingCount -= reduceBy
+	if order.RemainingCount <= 0 {
+		order.RemainingCount = 0
+		order.Status = Canceled
+	}
+	o := *order
+	return &o, nil
+}
+
+func (f *fakeClient) AmendOrder(ctx context.Context, orderID string, req AmendOrderRequest) (*AmendOrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, errFakeOrderNotFound()
+	}
+	resp := &AmendOrderResponse{OldOrder: *order}
+	filled := order.MakerFillCount + order.TakerFillCount
+	order.YesPrice = req.YesPrice
+	order.NoPrice = req.NoPrice
+	order.RemainingCount = req.Count - filled
+	resp.Order = *order
+	return resp, nil
+}
+
+func (f *fakeClient) GetFills(ctx context.Context, req FillsRequest) (*FillsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &FillsResponse{}
+	for _, fill := range f.fills {
+		if req.OrderID != "" && fill.OrderID != req.OrderID {
+			continue
+		}
+		if req.Ticker != "" && fill.Ticker != req.Ticker {
+			continue
+		}
+		if fill.CreatedTime.Before(req.MinTS.Time()) {
+			continue
+		}
+		resp.Fills = append(resp.Fills, fill)
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) GetTrades(ctx context.Context, req TradesRequest) (*TradesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &TradesResponse{}
+	for i := len(f.trades) - 1; i >= 0; i-- {
+		trade := f.trades[i]
+		if req.Ticker != "" && trade.Ticker != req.Ticker {
+			continue
+		}
+		if trade.CreatedTime.Unix() < int64(req.MinTS) {
+			continue
+		}
+		resp.Trades = append(resp.Trades, trade)
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) GetSettlements(ctx context.Context, req SettlementsRequest) (*SettlementsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &SettlementsResponse{}
+	for _, s := range f.settlements {
+		if s.SettledTime.Unix() < req.MinTs {
+			continue
+		}
+		resp.Settlements = append(resp.Settlements, s)
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) GetPositions(ctx context.Context, req PositionsRequest) (*PositionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &PositionsResponse{}
+	for _, p := range f.positions {
+		if req.Ticker != "" && p.Ticker != req.Ticker {
+			continue
+		}
+		if req.EventTicker != "" {
+			if m, ok := f.markets[p.Ticker]; !ok || m.EventTicker != req.EventTicker {
+				continue
+			}
+		}
+		resp.MarketPositions = append(resp.MarketPositions, p)
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) MarketOrderBook(ctx context.Context, ticker string) (*OrderBook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	book, ok := f.books[ticker]
+	if !ok {
+		return &OrderBook{}, nil
+	}
+	b := *book
+	return &b, nil
+}
+
+func (f *fakeClient) Market(ctx context.Context, ticker string) (*Market, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	market, ok := f.markets[ticker]
+	if !ok {
+		return nil, NewHttpError(http.StatusNotFound, "not found")
+	}
+	m := *market
+	return &m, nil
+}
+
+// fill executes count contracts of a resting order.
+func (f *fakeClient) fill(orderID string, count int) Fill {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	order := f.orders[orderID]
+	order.MakerFillCount += count
+	order.RemainingCount -= count
+	if order.RemainingCount == 0 {
+		order.Status = Executed
+	}
+
+	fill := Fill{
+		Action:      order.Action,
+		Count:       count,
+		CreatedTime: time.Now(),
+		NoPrice:     order.NoPrice,
+		OrderID:     orderID,
+		Side:        order.Side,
+		Ticker:      order.Ticker,
+		TradeID:     fmt.Sprintf("trade-%d", len(f.fills)+1),
+		YesPrice:    order.YesPrice,
+	}
+	f.fills = append(f.fills, fill)
+	return fill
+}
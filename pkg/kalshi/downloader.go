@@ -0,0 +1,334 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// ErrCheckpointMismatch is returned by Downloader.Download when the saved
+// checkpoint belongs to a different DownloadRequest.
+var ErrCheckpointMismatch = errors.New("checkpoint is for a different download")
+
+// DataSink receives the data downloaded by a Downloader. Each call is
+// followed by a checkpoint, so after an interruption the last batch may be
+// written again: sinks should ignore trades whose TradeID they already have.
+type DataSink interface {
+	WriteTrades(ctx context.Context, ticker string, trades []Trade) error
+	WriteHistory(ctx context.Context, ticker string, history []MarketHistory) error
+}
+
+// DownloadRequest selects the data to download.
+type DownloadRequest struct {
+	SeriesTicker string `json:"series_ticker"`
+	// MinTS and MaxTS bound the data downloaded, if set. Markets that
+	// closed before MinTS or opened after MaxTS are skipped.
+	MinTS time.Time `json:"min_ts,omitempty"`
+	MaxTS time.Time `json:"max_ts,omitempty"`
+	// SkipHistory skips MarketHistory, downloading trades only.
+	SkipHistory bool `json:"skip_history,omitempty"`
+}
+
+// Download stages, in order.
+const (
+	downloadTrades  = "trades"
+	downloadHistory = "history"
+)
+
+// DownloadCheckpoint is the progress of a Download, saved after every page
+// written to the sink.
+type DownloadCheckpoint struct {
+	Request DownloadRequest `json:"request"`
+	// EventsCursor is the cursor of the page of events in progress.
+	EventsCursor string `json:"events_cursor,omitempty"`
+	// Completed are the markets of the page of events that are done.
+	Completed map[string]bool `json:"completed,omitempty"`
+	// Market, Stage and Cursor are the position in the market in progress.
+	Market string `json:"market,omitempty"`
+	Stage  string `json:"stage,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	// LastTradeIDs are the trades of the last page written, which the next
+	// page repeats if trades were made in between.
+	LastTradeIDs []string `json:"last_trade_ids,omitempty"`
+	Done         bool     `json:"done,omitempty"`
+}
+
+// CheckpointStore persists a DownloadCheckpoint.
+type CheckpointStore interface {
+	// Load returns nil if no checkpoint was saved.
+	Load(ctx context.Context) (*DownloadCheckpoint, error)
+	Save(ctx context.Context, checkpoint *DownloadCheckpoint) error
+}
+
+// FileCheckpointStore stores a checkpoint as JSON in the file at its path.
+type FileCheckpointStore string
+
+var _ CheckpointStore = FileCheckpointStore("")
+
+// Load implements CheckpointStore.
+func (f FileCheckpointStore) Load(context.Context) (*DownloadCheckpoint, error) {
+	b, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	var checkpoint DownloadCheckpoint
+	if err := json.Unmarshal(b, &checkpoint); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// Save implements CheckpointStore. The file is replaced atomically.
+func (f FileCheckpointStore) Save(_ context.Context, checkpoint *DownloadCheckpoint) error {
+	b, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("tmp.Write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("tmp.Close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), string(f)); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
+
+// Downloader backfills the trades and history of the markets of a series,
+// walking Events, their markets, and then GetTrades and MarketHistory.
+// Requests rejected by the rate limiter are retried; other errors stop the
+// download, which resumes from its checkpoint when Download is called again.
+type Downloader struct {
+	// PageSize is the limit of every page requested, or the API's default
+	// if zero.
+	PageSize int
+
+	client      KalshiClientLogic
+	sink        DataSink
+	checkpoints CheckpointStore
+}
+
+// NewDownloader creates a Downloader writing to sink. checkpoints may be nil
+// to always start from the beginning.
+func NewDownloader(client KalshiClientLogic, sink DataSink, checkpoints CheckpointStore) *Downloader {
+	return &Downloader{
+		client:      client,
+		sink:        sink,
+		checkpoints: checkpoints,
+	}
+}
+
+// Download downloads the data selected by req, resuming from the saved
+// checkpoint if there is one.
+func (d *Downloader) Download(ctx context.Context, req DownloadRequest) error {
+	cp := &DownloadCheckpoint{Request: req}
+	if d.checkpoints != nil {
+		saved, err := d.checkpoints.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("d.checkpoints.Load: %w", err)
+		}
+		if saved != nil {
+			if !reflect.DeepEqual(normalizeDownloadRequest(saved.Request), normalizeDownloadRequest(req)) {
+				return ErrCheckpointMismatch
+			}
+			cp = saved
+		}
+	}
+	if cp.Completed == nil {
+		cp.Completed = make(map[string]bool)
+	}
+
+	for !cp.Done {
+		var resp *EventsResponse
+		err := retryRateLimited(ctx, func(ctx context.Context) (err error) {
+			resp, err = d.client.Events(ctx, EventsRequest{
+				CursorRequest: CursorRequest{Limit: d.PageSize, Cursor: cp.EventsCursor},
+				SeriesTicker:  req.SeriesTicker,
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("client.Events: %w", err)
+		}
+
+		for _, event := range resp.Events {
+			if err := d.downloadEvent(ctx, cp, event.EventTicker); err != nil {
+				return err
+			}
+		}
+
+		cp.EventsCursor = resp.Cursor
+		cp.Completed = make(map[string]bool)
+		cp.Done = resp.Cursor == ""
+		if err := d.save(ctx, cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeDownloadRequest drops the monotonic clock readings and locations
+// that don't survive a checkpoint's round trip through JSON.
+func normalizeDownloadRequest(req DownloadRequest) DownloadRequest {
+	req.MinTS, req.MaxTS = req.MinTS.UTC().Round(0), req.MaxTS.UTC().Round(0)
+	return req
+}
+
+func (d *Downloader) save(ctx context.Context, cp *DownloadCheckpoint) error {
+	if d.checkpoints == nil {
+		return nil
+	}
+	if err := d.checkpoints.Save(ctx, cp); err != nil {
+		return fmt.Errorf("d.checkpoints.Save: %w", err)
+	}
+	return nil
+}
+
+func (d *Downloader) downloadEvent(ctx context.Context, cp *DownloadCheckpoint, eventTicker string) error {
+	var resp *EventResponse
+	err := retryRateLimited(ctx, func(ctx context.Context) (err error) {
+		resp, err = d.client.Event(ctx, eventTicker)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("client.Event: %w", err)
+	}
+
+	req := cp.Request
+	for _, m := range resp.Markets {
+		if cp.Completed[m.Ticker] {
+			continue
+		}
+		skip := (!req.MinTS.IsZero() && !m.CloseTime.IsZero() && m.CloseTime.Before(req.MinTS)) ||
+			(!req.MaxTS.IsZero() && m.OpenTime.After(req.MaxTS))
+		if !skip {
+			if err := d.downloadMarket(ctx, cp, m.Ticker); err != nil {
+				return err
+			}
+		}
+		cp.Completed[m.Ticker] = true
+		cp.Market, cp.Stage, cp.Cursor, cp.LastTradeIDs = "", "", "", nil
+		if err := d.save(ctx, cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Downloader) downloadMarket(ctx context.Context, cp *DownloadCheckpoint, ticker string) error {
+	if cp.Market != ticker {
+		cp.Market, cp.Stage, cp.Cursor, cp.LastTradeIDs = ticker, downloadTrades, "", nil
+	}
+	if cp.Stage == downloadTrades {
+		if err := d.downloadTrades(ctx, cp); err != nil {
+			return err
+		}
+		cp.Stage, cp.Cursor, cp.LastTradeIDs = downloadHistory, "", nil
+	}
+	if cp.Stage == downloadHistory && !cp.Request.SkipHistory {
+		if err := d.downloadHistory(ctx, cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Downloader) downloadTrades(ctx context.Context, cp *DownloadCheckpoint) error {
+	req := TradesRequest{Ticker: cp.Market}
+	if !cp.Request.MinTS.IsZero() {
+		req.MinTS = int(cp.Request.MinTS.Unix())
+	}
+	if !cp.Request.MaxTS.IsZero() {
+		req.MaxTS = int(cp.Request.MaxTS.Unix())
+	}
+
+	// Trades made during the download shift the pages.
+	seen := make(map[string]bool)
+	for _, id := range cp.LastTradeIDs {
+		seen[id] = true
+	}
+	for {
+		req.CursorRequest = CursorRequest{Limit: d.PageSize, Cursor: cp.Cursor}
+		var resp *TradesResponse
+		err := retryRateLimited(ctx, func(ctx context.Context) (err error) {
+			resp, err = d.client.GetTrades(ctx, req)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("client.GetTrades: %w", err)
+		}
+
+		var (
+			trades []Trade
+			ids    []string
+		)
+		for _, trade := range resp.Trades {
+			ids = append(ids, trade.TradeID)
+			if seen[trade.TradeID] {
+				continue
+			}
+			seen[trade.TradeID] = true
+			trades = append(trades, trade)
+		}
+		if len(trades) > 0 {
+			if err := d.sink.WriteTrades(ctx, cp.Market, trades); err != nil {
+				return fmt.Errorf("sink.WriteTrades: %w", err)
+			}
+		}
+
+		if resp.Cursor == "" {
+			return nil
+		}
+		cp.Cursor, cp.LastTradeIDs = resp.Cursor, ids
+		if err := d.save(ctx, cp); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Downloader) downloadHistory(ctx context.Context, cp *DownloadCheckpoint) error {
+	req := MarketHistoryRequest{
+		MinTS: Timestamp(cp.Request.MinTS),
+		MaxTS: Timestamp(cp.Request.MaxTS),
+	}
+	for {
+		req.CursorRequest = CursorRequest{Limit: d.PageSize, Cursor: cp.Cursor}
+		var resp *MarketHistoryResponse
+		err := retryRateLimited(ctx, func(ctx context.Context) (err error) {
+			resp, err = d.client.MarketHistory(ctx, cp.Market, req)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("client.MarketHistory: %w", err)
+		}
+
+		if len(resp.History) > 0 {
+			if err := d.sink.WriteHistory(ctx, cp.Market, resp.History); err != nil {
+				return fmt.Errorf("sink.WriteHistory: %w", err)
+			}
+		}
+
+		if resp.Cursor == "" {
+			return nil
+		}
+		cp.Cursor = resp.Cursor
+		if err := d.save(ctx, cp); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package kalshi
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pageOf returns the page of items at cursor, an index, and the next cursor.
+func pageOf[T any](items []T, cursor string, limit int) ([]T, string) {
+	i, _ := strconv.Atoi(cursor)
+	end := min(i+limit, len(items))
+	if end == len(items) {
+		return items[i:end], ""
+	}
+	return items[i:end], strconv.Itoa(end)
+}
+
+type downloadClient struct {
+	KalshiClientLogic
+	events  []Event
+	markets map[string][]Market
+	trades  map[string][]Trade
+	history map[string][]MarketHistory
+
+	// failTradesCall fails that call to GetTrades, counting from one, and
+	// rate limits the one before.
+	failTradesCall int
+	tradesCalls    int
+	tradesReqs     []TradesRequest
+	eventsCalls    int
+}
+
+func (c *downloadClient) Events(_ context.Context, req EventsRequest) (*EventsResponse, error) {
+	c.eventsCalls++
+	events, cursor := pageOf(c.events, req.Cursor, req.Limit)
+	return &EventsResponse{CursorResponse: CursorResponse{Cursor: cursor}, Events: events}, nil
+}
+
+func (c *downloadClient) Event(_ context.Context, event string) (*EventResponse, error) {
+	return &EventResponse{Event: Event{EventTicker: event}, Markets: c.markets[event]}, nil
+}
+
+func (c *downloadClient) GetTrades(_ context.Context, req TradesRequest) (*TradesResponse, error) {
+	c.tradesCalls++
+	switch c.tradesCalls {
+	case c.failTradesCall - 1:
+		return nil, ErrRateLimitExceeded
+	case c.failTradesCall:
+		return nil, NewHttpError(http.StatusInternalServerError, "boom")
+	}
+	c.tradesReqs = append(c.tradesReqs, req)
+	trades, cursor := pageOf(c.trades[req.Ticker], req.Cursor, req.Limit)
+	return &TradesResponse{CursorResponse: CursorResponse{Cursor: cursor}, Trades: trades}, nil
+}
+
+func (c *downloadClient) MarketHistory(_ context.Context, ticker string, req MarketHistoryRequest) (*MarketHistoryResponse, error) {
+	history, cursor := pageOf(c.history[ticker], req.Cursor, req.Limit)
+	return &MarketHistoryResponse{CursorResponse: CursorResponse{Cursor: cursor}, History: history, Ticker: ticker}, nil
+}
+
+type memorySink struct {
+	trades  map[string][]string
+	history map[string]int
+}
+
+func (s *memorySink) WriteTrades(_ context.Context, ticker string, trades []Trade) error {
+	for _, trade := range trades {
+		s.trades[ticker] = append(s.trades[ticker], trade.TradeID)
+	}
+	return nil
+}
+
+func (s *memorySink) WriteHistory(_ context.Context, ticker string, history []MarketHistory) error {
+	s.history[ticker] += len(history)
+	return nil
+}
+
+func TestDownloader(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trade := func(id string) Trade { return Trade{TradeID: id} }
+	client := &downloadClient{
+		events: []Event{{EventTicker: "S-1"}, {EventTicker: "S-2"}},
+		markets: map[string][]Market{
+			"S-1": {
+				{Ticker: "S-1-A", OpenTime: start, CloseTime: start.Add(48 * time.Hour)},
+				// Closed before the range.
+				{Ticker: "S-1-OLD", OpenTime: start.Add(-48 * time.Hour), CloseTime: start.Add(-24 * time.Hour)},
+			},
+			"S-2": {{Ticker: "S-2-A", OpenTime: start, CloseTime: start.Add(48 * time.Hour)}},
+		},
+		trades: map[string][]Trade{
+			// A trade made during the download shifts t2 onto the next page.
+			"S-1-A":   {trade("t1"), trade("t2"), trade("t2"), trade("t3")},
+			"S-1-OLD": {trade("old")},
+			"S-2-A":   {trade("u1"), trade("u2")},
+		},
+		history: map[string][]MarketHistory{
+			"S-1-A": make([]MarketHistory, 3),
+			"S-2-A": make([]MarketHistory, 1),
+		},
+		failTradesCall: 4,
+	}
+	sink := &memorySink{trades: make(map[string][]string), history: make(map[string]int)}
+	store := FileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	d := NewDownloader(client, sink, store)
+	d.PageSize = 1
+	req := DownloadRequest{SeriesTicker: "S", MinTS: start, MaxTS: start.Add(24 * time.Hour)}
+
+	ctx := context.Background()
+	err := d.Download(ctx, req)
+	require.ErrorContains(t, err, "boom")
+
+	cp, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "S-1-A", cp.Market)
+	require.Equal(t, downloadTrades, cp.Stage)
+	require.Equal(t, "2", cp.Cursor)
+
+	// The second run resumes where the first stopped.
+	require.NoError(t, d.Download(ctx, req))
+	require.Equal(t, map[string][]string{
+		"S-1-A": {"t1", "t2", "t3"},
+		"S-2-A": {"u1", "u2"},
+	}, sink.trades)
+	require.Equal(t, map[string]int{"S-1-A": 3, "S-2-A": 1}, sink.history)
+	for _, r := range client.tradesReqs {
+		require.Equal(t, int(start.Unix()), r.MinTS)
+		require.Equal(t, int(start.Add(24*time.Hour).Unix()), r.MaxTS)
+	}
+
+	// Finished downloads aren't repeated.
+	calls := client.eventsCalls
+	require.NoError(t, d.Download(ctx, req))
+	require.Equal(t, calls, client.eventsCalls)
+
+	req.SeriesTicker = "OTHER"
+	require.ErrorIs(t, d.Download(ctx, req), ErrCheckpointMismatch)
+}
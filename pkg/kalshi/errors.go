@@ -1,22 +1,68 @@
 package kalshi
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 )
 
 var (
-	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrRateLimitExceeded      = errors.New("rate limit exceeded")
+	ErrOrderNotTracked        = errors.New("order not tracked")
+	ErrDuplicateClientOrderID = errors.New("duplicate client order id")
 )
 
+// Errors reported by the API. *HttpError and *APIError match them with
+// errors.Is.
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrMarketClosed        = errors.New("market closed")
+	ErrOrderNotFound       = errors.New("order not found")
+	// ErrRateLimited is the API rejecting a request as over its rate limit.
+	// Unlike ErrRateLimitExceeded, the request reached the API.
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// isRateLimited reports whether err is a request turned away by either the
+// client-side rate limiter or the API's.
+func isRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimitExceeded) || errors.Is(err, ErrRateLimited)
+}
+
+// errorCodes maps Kalshi error codes to the errors they match.
+var errorCodes = map[string]error{
+	"insufficient_balance": ErrInsufficientBalance,
+	"market_closed":        ErrMarketClosed,
+	"order_not_found":      ErrOrderNotFound,
+	"too_many_requests":    ErrRateLimited,
+	"unauthorized":         ErrUnauthorized,
+}
+
+// HttpError is returned for responses with an error status. If the body is
+// Kalshi's JSON error envelope, it is decoded into ErrorCode, Message and
+// Details; otherwise Message holds the raw body.
 type HttpError struct {
+	// Code is the HTTP status code.
 	Code    int    `json:"code"`
 	Status  string `json:"status"`
 	Message string `json:"message"`
+	// ErrorCode is Kalshi's error code, e.g. "insufficient_balance".
+	ErrorCode string `json:"error_code,omitempty"`
+	Details   string `json:"details,omitempty"`
+	// RequestID identifies the request to Kalshi support.
+	RequestID string `json:"request_id,omitempty"`
+
+	// notFound is the error a 404 without an ErrorCode matches, set for
+	// requests about a single resource, e.g. an order.
+	notFound error
 }
 
 func (e *HttpError) Error() string {
+	if e.ErrorCode != "" {
+		return fmt.Sprintf("http %d: %s: %s", e.Code, e.ErrorCode, e.Message)
+	}
 	return fmt.Sprintf("http %d: %s", e.Code, e.Message)
 }
 
@@ -24,6 +70,35 @@ func (e *HttpError) IsClientErr() bool {
 	return e.Code > 399 && e.Code < 500
 }
 
+// IsRetryable reports whether the request may succeed if sent again as is:
+// on rate limiting, timeouts and server errors.
+func (e *HttpError) IsRetryable() bool {
+	switch e.Code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return e.Code >= 500 && e.Code != http.StatusNotImplemented
+}
+
+// Is reports whether e matches one of the API error sentinels, by its
+// ErrorCode or, failing that, its status code. A 404 without a recognized
+// ErrorCode only matches ErrOrderNotFound if it answers a request for an
+// order.
+func (e *HttpError) Is(target error) bool {
+	if err, ok := errorCodes[e.ErrorCode]; ok {
+		return err == target
+	}
+	switch e.Code {
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusNotFound:
+		return e.notFound != nil && target == e.notFound
+	}
+	return false
+}
+
 func NewHttpError(code int, message string) *HttpError {
 	return &HttpError{
 		Code:    code,
@@ -31,3 +106,58 @@ func NewHttpError(code int, message string) *HttpError {
 		Message: message,
 	}
 }
+
+// newHttpErrorFromResponse creates an HttpError from an error response and
+// its body.
+func newHttpErrorFromResponse(resp *http.Response, body []byte) *HttpError {
+	e := NewHttpError(resp.StatusCode, string(body))
+	e.RequestID = resp.Header.Get("X-Request-Id")
+
+	var envelope struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil && envelope.Error.Code != "" {
+		e.ErrorCode = envelope.Error.Code
+		e.Message = envelope.Error.Message
+		e.Details = envelope.Error.Details
+	}
+	return e
+}
+
+// APIError is an error reported by the Kalshi API for a single item of a
+// request, e.g. one order of a batch.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+	Service string `json:"service,omitempty"`
+}
+
+// Is reports whether e matches one of the API error sentinels by its Code.
+func (e *APIError) Is(target error) bool {
+	err, ok := errorCodes[e.Code]
+	return ok && err == target
+}
+
+func (e *APIError) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("%s: %s (%s)", e.Code, e.Message, e.Details)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// UnknownOrderOutcomeError is returned when it can't be determined whether an
+// order was placed. The order should be looked up by ClientOrderID before it
+// is sent again.
+type UnknownOrderOutcomeError struct {
+	ClientOrderID string
+	Err           error
+}
+
+func (e *UnknownOrderOutcomeError) Error() string {
+	return fmt.Sprintf("unknown outcome for order %s: %v", e.ClientOrderID, e.Err)
+}
+
+func (e *UnknownOrderOutcomeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,79 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpErrorDecoding(t *testing.T) {
+	t.Parallel()
+
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/portfolio/orders":
+			w.Header().Set("X-Request-Id", "req-1")
+			writeJSON(t, w, http.StatusBadRequest, map[string]any{
+				"error": map[string]string{
+					"code":    "insufficient_balance",
+					"message": "not enough funds",
+					"details": "balance 100, required 500",
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream unavailable"))
+		}
+	}))
+
+	_, err := client.CreateOrder(context.Background(), CreateOrderRequest{Ticker: "A", Count: 5, Side: Yes})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.NotErrorIs(t, err, ErrMarketClosed)
+
+	var httpErr *HttpError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	require.Equal(t, "insufficient_balance", httpErr.ErrorCode)
+	require.Equal(t, "not enough funds", httpErr.Message)
+	require.Equal(t, "balance 100, required 500", httpErr.Details)
+	require.Equal(t, "req-1", httpErr.RequestID)
+	require.False(t, httpErr.IsRetryable())
+
+	// Bodies that aren't an error envelope are kept as the message.
+	_, err = client.GetBalance(context.Background())
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, "upstream unavailable", httpErr.Message)
+	require.Empty(t, httpErr.ErrorCode)
+	require.True(t, httpErr.IsRetryable())
+}
+
+func TestHttpErrorIs(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		err       *HttpError
+		target    error
+		retryable bool
+	}{
+		{&HttpError{Code: http.StatusBadRequest, ErrorCode: "market_closed"}, ErrMarketClosed, false},
+		{&HttpError{Code: http.StatusNotFound, ErrorCode: "order_not_found"}, ErrOrderNotFound, false},
+		{&HttpError{Code: http.StatusNotFound, notFound: ErrOrderNotFound}, ErrOrderNotFound, false},
+		{NewHttpError(http.StatusTooManyRequests, "slow down"), ErrRateLimited, true},
+		{NewHttpError(http.StatusUnauthorized, "bad signature"), ErrUnauthorized, false},
+		{NewHttpError(http.StatusServiceUnavailable, "down"), nil, true},
+	} {
+		if tt.target != nil {
+			require.ErrorIs(t, tt.err, tt.target, tt.err.Error())
+		}
+		require.Equal(t, tt.retryable, tt.err.IsRetryable(), tt.err.Error())
+	}
+
+	// Server-side rate limiting is told apart from the client-side limiter,
+	// and a 404 only means a missing order if an order was requested.
+	require.False(t, errors.Is(NewHttpError(http.StatusTooManyRequests, ""), ErrRateLimitExceeded))
+	require.False(t, errors.Is(NewHttpError(http.StatusNotFound, "not found"), ErrOrderNotFound))
+	require.ErrorIs(t, &APIError{Code: "insufficient_balance"}, ErrInsufficientBalance)
+}
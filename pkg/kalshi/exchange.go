@@ -11,43 +11,40 @@ type ExchangeStatusResponse struct {
 
 // ExchangeScheduleResponse is described here:
 // https://trading-api.readme.io/reference/getexchangeschedule.
+//
+// Use Parse to query it.
 type ExchangeScheduleResponse struct {
-	Schedule struct {
-		StandardHours struct {
-			Monday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"monday"`
-			Tuesday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"tuesday"`
-			Wednesday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"wednesday"`
-			Thursday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"thursday"`
-			Friday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"friday"`
-			Saturday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"saturday"`
-			Sunday struct {
-				OpenTime  string `json:"open_time"`
-				CloseTime string `json:"close_time"`
-			} `json:"sunday"`
-		} `json:"standard_hours"`
-		MaintenanceWindows []struct {
-			EndDatetime   string `json:"end_datetime"`
-			StartDatetime string `json:"start_datetime"`
-		} `json:"maintenance_windows,omitempty"`
-	} `json:"schedule"`
+	Schedule RawExchangeSchedule `json:"schedule"`
+}
+
+// RawExchangeSchedule is the schedule as returned by the API.
+type RawExchangeSchedule struct {
+	StandardHours      StandardHours          `json:"standard_hours"`
+	MaintenanceWindows []RawMaintenanceWindow `json:"maintenance_windows,omitempty"`
+}
+
+// StandardHours are the weekly trading hours.
+type StandardHours struct {
+	Monday    RawTradingHours `json:"monday"`
+	Tuesday   RawTradingHours `json:"tuesday"`
+	Wednesday RawTradingHours `json:"wednesday"`
+	Thursday  RawTradingHours `json:"thursday"`
+	Friday    RawTradingHours `json:"friday"`
+	Saturday  RawTradingHours `json:"saturday"`
+	Sunday    RawTradingHours `json:"sunday"`
+}
+
+// RawTradingHours are a day's trading hours as times of day, e.g. "08:00",
+// in the exchange's timezone.
+type RawTradingHours struct {
+	OpenTime  string `json:"open_time"`
+	CloseTime string `json:"close_time"`
+}
+
+// RawMaintenanceWindow is a maintenance window with RFC 3339 datetimes.
+type RawMaintenanceWindow struct {
+	EndDatetime   string `json:"end_datetime"`
+	StartDatetime string `json:"start_datetime"`
 }
 
 // ExchangeStatus is described here:
@@ -55,6 +52,7 @@ type ExchangeScheduleResponse struct {
 func (c *Client) ExchangeStatus(ctx context.Context) (*ExchangeStatusResponse, error) {
 	var resp ExchangeStatusResponse
 	err := c.request(ctx, request{
+		Name:         "ExchangeStatus",
 		Method:       "GET",
 		Endpoint:     "exchange/status",
 		JSONResponse: &resp,
@@ -70,6 +68,7 @@ func (c *Client) ExchangeStatus(ctx context.Context) (*ExchangeStatusResponse, e
 func (c *Client) ExchangeSchedule(ctx context.Context) (*ExchangeScheduleResponse, error) {
 	var resp ExchangeScheduleResponse
 	err := c.request(ctx, request{
+		Name:         "ExchangeSchedule",
 		Method:       "GET",
 		Endpoint:     "exchange/schedule",
 		JSONResponse: &resp,
@@ -3,10 +3,14 @@ package kalshi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"sort"
+	"sync"
 	"time"
 
 	"nhooyr.io/websocket"
@@ -18,7 +22,123 @@ import (
 // https://trading-api.readme.io/reference/introduction.
 // WARNING: Feed has not been thoroughly tested.
 type Feed struct {
-	c *websocket.Conn
+	// DropWhenFull makes Book drop updates the consumer isn't ready to
+	// receive instead of blocking. Every update is a full book, so only
+	// intermediate states are lost.
+	DropWhenFull bool
+	// MaxReconnects is how many times in a row Book reopens the connection
+	// when it fails, resubscribing on the new one. It defaults to 3 for
+	// feeds opened with OpenFeed. Zero disables reconnecting.
+	MaxReconnects int
+	// ReconnectDelay is the wait before reopening the connection. It
+	// doubles after each failed attempt, and defaults to a second for
+	// feeds opened with OpenFeed.
+	ReconnectDelay time.Duration
+
+	observers feedObservers
+	logger    *slog.Logger
+	// dial opens a new connection. It is nil for replays.
+	dial     func(ctx context.Context) (feedConn, error)
+	recorder *FeedRecorder
+
+	mu     sync.Mutex
+	c      feedConn
+	closed bool
+}
+
+// FeedObserver is notified of Feed activity, e.g. to export metrics. Its
+// methods must not block.
+type FeedObserver interface {
+	// MessageReceived is called for every message, by type, e.g.
+	// "orderbook_delta".
+	MessageReceived(messageType string)
+	// SequenceGap is called when a message is missed, before Book returns
+	// an error.
+	SequenceGap(marketTicker string)
+	// Reconnected is called when Book has reopened a failed connection,
+	// before it resubscribes to marketTicker.
+	Reconnected(marketTicker string)
+	// DeliveryDropped is called when an update is dropped because of
+	// DropWhenFull.
+	DeliveryDropped(marketTicker string)
+}
+
+// SubscriptionObserver may be implemented by a FeedObserver to follow the
+// lifecycle of subscriptions, e.g. to trace them. Subscribe is called when a
+// subscription starts, with the context it was made with, and returns the
+// context to use for it and a function called with its error when it ends.
+type SubscriptionObserver interface {
+	Subscribe(ctx context.Context, channel, marketTicker string) (context.Context, func(err error))
+}
+
+// ObserveFeeds adds observers notified of the activity of feeds opened with
+// OpenFeed. ObserveFeeds must not be called concurrently with OpenFeed.
+func (c *Client) ObserveFeeds(observers ...FeedObserver) {
+	c.feedObservers = append(c.feedObservers, observers...)
+}
+
+type feedObservers []FeedObserver
+
+func (o feedObservers) MessageReceived(messageType string) {
+	for _, observer := range o {
+		observer.MessageReceived(messageType)
+	}
+}
+
+func (o feedObservers) SequenceGap(marketTicker string) {
+	for _, observer := range o {
+		observer.SequenceGap(marketTicker)
+	}
+}
+
+func (o feedObservers) Reconnected(marketTicker string) {
+	for _, observer := range o {
+		observer.Reconnected(marketTicker)
+	}
+}
+
+func (o feedObservers) DeliveryDropped(marketTicker string) {
+	for _, observer := range o {
+		observer.DeliveryDropped(marketTicker)
+	}
+}
+
+// subscribe starts a subscription with every SubscriptionObserver. The
+// returned function ends it in reverse order.
+func (o feedObservers) subscribe(ctx context.Context, channel, marketTicker string) (context.Context, func(err error)) {
+	var ends []func(err error)
+	for _, observer := range o {
+		if so, ok := observer.(SubscriptionObserver); ok {
+			var end func(err error)
+			ctx, end = so.Subscribe(ctx, channel, marketTicker)
+			ends = append(ends, end)
+		}
+	}
+	return ctx, func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
+
+// deliver sends book to feed, or drops it if DropWhenFull is set and feed
+// isn't ready.
+func (s *Feed) deliver(ctx context.Context, feed chan<- *StreamOrderBook, book *StreamOrderBook) error {
+	if s.DropWhenFull {
+		select {
+		case feed <- book:
+		default:
+			s.observers.DeliveryDropped(book.MarketID)
+			s.log().LogAttrs(ctx, slog.LevelDebug, "feed update dropped", slog.String("ticker", book.MarketID))
+		}
+		return nil
+	}
+	select {
+	case feed <- book:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 type commandParams struct {
@@ -32,7 +152,53 @@ type command struct {
 }
 
 func (s *Feed) sendCommand(ctx context.Context, c command) error {
-	return wsjson.Write(ctx, s.c, c)
+	return s.c.Write(ctx, c)
+}
+
+// feedConn is the connection of a Feed: a websocket or a recording being
+// replayed.
+type feedConn interface {
+	// Read returns the next message and when it was received.
+	Read(ctx context.Context) (message []byte, receivedAt time.Time, err error)
+	Write(ctx context.Context, v any) error
+	Close() error
+}
+
+// feedConnError is a failure of a Feed's connection, which Book recovers
+// from by reconnecting.
+type feedConnError struct {
+	err error
+}
+
+func (e *feedConnError) Error() string {
+	return e.err.Error()
+}
+
+func (e *feedConnError) Unwrap() error {
+	return e.err
+}
+
+type wsConn struct {
+	c *websocket.Conn
+}
+
+func (c wsConn) Read(ctx context.Context) ([]byte, time.Time, error) {
+	_, message, err := c.c.Read(ctx)
+	if err != nil {
+		return nil, time.Time{}, &feedConnError{err}
+	}
+	return message, time.Now(), nil
+}
+
+func (c wsConn) Write(ctx context.Context, v any) error {
+	if err := wsjson.Write(ctx, c.c, v); err != nil {
+		return &feedConnError{err}
+	}
+	return nil
+}
+
+func (c wsConn) Close() error {
+	return c.c.Close(websocket.StatusNormalClosure, "")
 }
 
 type subscribedResponse struct {
@@ -121,8 +287,14 @@ func (o *orderBookStreamState) LoadBook(book OrderBook) {
 
 // OrderBook returns a canonical OrderBook from the stream state.
 func (o *orderBookStreamState) OrderBook() *StreamOrderBook {
+	return o.OrderBookAt(time.Now())
+}
+
+// OrderBookAt returns a canonical OrderBook from the stream state, loaded
+// at loadedAt.
+func (o *orderBookStreamState) OrderBookAt(loadedAt time.Time) *StreamOrderBook {
 	ob := StreamOrderBook{
-		LoadedAt: time.Now(),
+		LoadedAt: loadedAt,
 		MarketID: o.MarketID,
 	}
 
@@ -171,59 +343,147 @@ type StreamOrderBook struct {
 	MarketID string
 }
 
-// Book instantiates a streaming order book feed for market.
-func (s *Feed) Book(ctx context.Context, marketTicker string, feed chan<- *StreamOrderBook) error {
+// Book instantiates a streaming order book feed for market. If the
+// connection fails, Book reconnects up to MaxReconnects times in a row and
+// resubscribes, starting over from a new snapshot.
+func (s *Feed) Book(ctx context.Context, marketTicker string, feed chan<- *StreamOrderBook) (err error) {
+	const channel = "orderbook_delta"
+	ctx, end := s.observers.subscribe(ctx, channel, marketTicker)
+	logger := s.log().With(slog.String("channel", channel), slog.String("ticker", marketTicker))
+	defer func() {
+		end(err)
+		// Subscriptions end when the caller cancels them.
+		if errors.Is(err, context.Canceled) {
+			logger.LogAttrs(ctx, slog.LevelInfo, "unsubscribed")
+		} else {
+			logger.LogAttrs(ctx, slog.LevelWarn, "subscription ended", slog.Any("error", err))
+		}
+	}()
+
+	for failures := 0; ; {
+		var subscribed bool
+		subscribed, err = s.book(ctx, logger, marketTicker, feed)
+		if subscribed {
+			failures = 0
+		}
+		var connErr *feedConnError
+		if !errors.As(err, &connErr) || s.dial == nil || ctx.Err() != nil {
+			return err
+		}
+		for {
+			if failures >= s.MaxReconnects {
+				return err
+			}
+			failures++
+			delay := s.ReconnectDelay << (failures - 1)
+			logger.LogAttrs(ctx, slog.LevelWarn, "feed reconnecting",
+				slog.Int("attempt", failures), slog.Duration("delay", delay), slog.Any("error", err))
+			reconnectErr := s.reconnect(ctx, delay)
+			if reconnectErr == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			err = fmt.Errorf("reconnect: %w", reconnectErr)
+		}
+		s.observers.Reconnected(marketTicker)
+		logger.LogAttrs(ctx, slog.LevelInfo, "feed reconnected", slog.Int("attempt", failures))
+	}
+}
+
+// reconnect replaces the connection once delay has passed.
+func (s *Feed) reconnect(ctx context.Context, delay time.Duration) error {
+	s.mu.Lock()
+	s.c.Close()
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+	}
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		conn.Close()
+		return net.ErrClosed
+	}
+	s.c = conn
+	if s.recorder != nil {
+		s.c = recordingConn{feedConn: conn, recorder: s.recorder}
+	}
+	return nil
+}
+
+// book subscribes to the book of marketTicker on the current connection and
+// streams it. It reports whether the subscription was made.
+func (s *Feed) book(ctx context.Context, logger *slog.Logger, marketTicker string, feed chan<- *StreamOrderBook) (subscribed bool, err error) {
+	const channel = "orderbook_delta"
 	id := 1
-	err := s.sendCommand(ctx, command{
+	err = s.sendCommand(ctx, command{
 		ID:      1,
 		Command: "subscribe",
 		Params: commandParams{
 			Channels: []string{
-				"orderbook_delta",
+				channel,
 			},
 			MarketTicker: marketTicker,
 		},
 	})
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	var r subscribedResponse
-	err = wsjson.Read(ctx, s.c, &r)
+	message, _, err := s.c.Read(ctx)
 	if err != nil {
-		return err
+		return false, err
+	}
+	var r subscribedResponse
+	if err := json.Unmarshal(message, &r); err != nil {
+		return false, fmt.Errorf("unmarshal subscribed: %w", err)
 	}
 
 	if r.Type != "subscribed" {
-		return fmt.Errorf("unexpected message: %+v", r)
+		return false, fmt.Errorf("unexpected message: %+v", r)
 	}
 
 	if r.ID != id {
-		return fmt.Errorf("unexpected id: %+v", id)
+		return false, fmt.Errorf("unexpected id: %+v", id)
 	}
 
 	sid := r.Msg.Sid
 	wantSeq := 1
+	logger.LogAttrs(ctx, slog.LevelInfo, "subscribed", slog.Int("sid", sid))
 
 	orderBookState := makeOrderBookStreamState(marketTicker)
 
 	for {
-		_, message, err := s.c.Read(ctx)
+		message, receivedAt, err := s.c.Read(ctx)
 		if err != nil {
-			return fmt.Errorf("read message: %w", err)
+			return true, fmt.Errorf("read message: %w", err)
 		}
 
 		var header subscriptionMessageHeader
 		err = json.Unmarshal(message, &header)
 		if err != nil {
-			return fmt.Errorf("read header: %+v", err)
+			return true, fmt.Errorf("read header: %+v", err)
 		}
 
+		s.observers.MessageReceived(header.Type)
 		if header.Sid != sid {
-			return fmt.Errorf("unexpected sid %v", header.Sid)
+			return true, fmt.Errorf("unexpected sid %v", header.Sid)
 		}
 		if header.Seq != wantSeq {
-			return fmt.Errorf("unexpected sequence %v, want %v", header.Seq, wantSeq)
+			s.observers.SequenceGap(marketTicker)
+			logger.LogAttrs(ctx, slog.LevelWarn, "feed sequence gap",
+				slog.Int("seq", header.Seq), slog.Int("want_seq", wantSeq))
+			return true, fmt.Errorf("unexpected sequence %v, want %v", header.Seq, wantSeq)
 		}
 		wantSeq++
 
@@ -232,49 +492,79 @@ func (s *Feed) Book(ctx context.Context, marketTicker string, feed chan<- *Strea
 			var snapshot orderBookSnapshot
 			err = json.Unmarshal(message, &snapshot)
 			if err != nil {
-				return fmt.Errorf("unmarshal snapshot: %w", err)
+				return true, fmt.Errorf("unmarshal snapshot: %w", err)
 			}
 			ob := OrderBook{
 				YesBids: snapshot.Msg.Yes,
 				NoBids:  snapshot.Msg.No,
 			}
 			orderBookState.LoadBook(ob)
-			feed <- orderBookState.OrderBook()
+			if err := s.deliver(ctx, feed, orderBookState.OrderBookAt(receivedAt)); err != nil {
+				return true, err
+			}
 		case "orderbook_delta":
 			var delta orderBookDelta
 			err = json.Unmarshal(message, &delta)
 			if err != nil {
-				return fmt.Errorf("unmarshal delta: %w", err)
+				return true, fmt.Errorf("unmarshal delta: %w", err)
 			}
 			err = orderBookState.ApplyDelta(
 				delta.Msg.Side, delta.Msg.Price, delta.Msg.Delta,
 			)
 			if err != nil {
-				return fmt.Errorf("apply delta: %w", err)
+				return true, fmt.Errorf("apply delta: %w", err)
+			}
+			if err := s.deliver(ctx, feed, orderBookState.OrderBookAt(receivedAt)); err != nil {
+				return true, err
 			}
-			feed <- orderBookState.OrderBook()
 		case "error":
 			var errMsg errorMessage
 			err = json.Unmarshal(message, &errMsg)
 			if err != nil {
-				return fmt.Errorf("unmarshal error: %w", err)
+				return true, fmt.Errorf("unmarshal error: %w", err)
 			}
-			return fmt.Errorf("error message (%v): %v", errMsg.Msg.Code, errMsg.Msg.Msg)
+			return true, fmt.Errorf("error message (%v): %v", errMsg.Msg.Code, errMsg.Msg.Msg)
 		default:
-			return fmt.Errorf("unexpected type %q", header.Type)
+			return true, fmt.Errorf("unexpected type %q", header.Type)
 		}
 	}
 }
 
 func (f *Feed) Close() error {
-	return f.c.Close(websocket.StatusNormalClosure, "")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return f.c.Close()
 }
 
+const (
+	defaultFeedReconnects     = 3
+	defaultFeedReconnectDelay = time.Second
+)
+
 // OpenFeed creates a new market data streaming connection.
 // OpenFeed is described in more detail here:
 // https://trading-api.readme.io/reference/introduction.
 // WARNING: OpenFeed has not been thoroughly tested.
 func (c *Client) OpenFeed(ctx context.Context) (*Feed, error) {
+	conn, err := c.dialFeed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.log().LogAttrs(ctx, slog.LevelInfo, "feed opened")
+	return &Feed{
+		MaxReconnects:  defaultFeedReconnects,
+		ReconnectDelay: defaultFeedReconnectDelay,
+		c:              conn,
+		dial:           c.dialFeed,
+		observers:      feedObservers(c.feedObservers),
+		logger:         c.logger,
+	}, nil
+}
+
+// dialFeed opens a streaming connection, for OpenFeed and for Book's
+// reconnections.
+func (c *Client) dialFeed(ctx context.Context) (_ feedConn, err error) {
 	// Convert BaseURL to a websocket URL.
 	u, err := url.Parse(c.BaseURL)
 	if err != nil {
@@ -283,12 +573,43 @@ func (c *Client) OpenFeed(ctx context.Context) (*Feed, error) {
 	u.Scheme = "wss"
 	u.Path = "trade-api/ws/v2"
 
-	conn, resp, err := websocket.Dial(ctx,
+	// The handshake is passed through the middleware as a GET request,
+	// whose headers and context are used for the dial.
+	info := RequestInfo{
+		Name:     "OpenFeed",
+		Method:   http.MethodGet,
+		Endpoint: u.Path,
+		Attempt:  attemptFrom(ctx),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	start := time.Now()
+	var statusCode, ran int
+	defer func() {
+		c.afterResponse(ctx, req, ran, ResponseInfo{
+			RequestInfo: info,
+			StatusCode:  statusCode,
+			Latency:     time.Since(start),
+			Err:         err,
+		})
+	}()
+	if req, ran, err = c.beforeRequest(req, info); err != nil {
+		return nil, fmt.Errorf("c.beforeRequest: %w", err)
+	}
+
+	conn, resp, err := websocket.Dial(req.Context(),
 		u.String(),
 		&websocket.DialOptions{
 			HTTPClient: c.httpClient,
+			HTTPHeader: req.Header,
 		},
 	)
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
 	if err != nil {
 		return nil, fmt.Errorf("dial %q: %w", u.String(), err)
 	}
@@ -296,5 +617,5 @@ func (c *Client) OpenFeed(ctx context.Context) (*Feed, error) {
 		return nil, fmt.Errorf("websocket refused: %v", resp.Status)
 	}
 
-	return &Feed{c: conn}, nil
+	return wsConn{conn}, nil
 }
@@ -2,6 +2,10 @@ package kalshi
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"reflect"
 	"sort"
@@ -227,3 +231,103 @@ func TestFeed(t *testing.T) {
 		}
 	})
 }
+
+type countingObserver struct {
+	FeedObserver
+	dropped     []string
+	reconnected []string
+}
+
+func (o *countingObserver) MessageReceived(string) {}
+
+func (o *countingObserver) Reconnected(marketTicker string) {
+	o.reconnected = append(o.reconnected, marketTicker)
+}
+
+func (o *countingObserver) DeliveryDropped(marketTicker string) {
+	o.dropped = append(o.dropped, marketTicker)
+}
+
+func TestFeedDeliver(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	observer := &countingObserver{}
+	f := &Feed{DropWhenFull: true, observers: feedObservers{observer}}
+	ch := make(chan *StreamOrderBook, 1)
+
+	require.NoError(t, f.deliver(ctx, ch, &StreamOrderBook{MarketID: "A"}))
+	require.NoError(t, f.deliver(ctx, ch, &StreamOrderBook{MarketID: "A"}))
+	require.Len(t, ch, 1)
+	require.Equal(t, []string{"A"}, observer.dropped)
+
+	// Without DropWhenFull, delivery blocks until ctx is done.
+	f.DropWhenFull = false
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, f.deliver(ctx, ch, &StreamOrderBook{MarketID: "A"}), context.DeadlineExceeded)
+}
+
+// droppingConn fails like a dropped connection once its messages run out.
+type droppingConn struct {
+	*scriptedConn
+}
+
+func (c droppingConn) Read(ctx context.Context) ([]byte, time.Time, error) {
+	message, at, err := c.scriptedConn.Read(ctx)
+	if errors.Is(err, io.EOF) {
+		err = &feedConnError{io.ErrUnexpectedEOF}
+	}
+	return message, at, err
+}
+
+func TestFeedReconnect(t *testing.T) {
+	t.Parallel()
+
+	errRefused := errors.New("refused")
+	var dials int
+	observer := &countingObserver{}
+	f := &Feed{
+		MaxReconnects:  2,
+		ReconnectDelay: time.Millisecond,
+		c:              droppingConn{testScriptedConn(time.Now(), 0)},
+		observers:      feedObservers{observer},
+		dial: func(context.Context) (feedConn, error) {
+			dials++
+			// The second connection drops too, after which the
+			// feed can't be reopened.
+			if dials > 1 {
+				return nil, errRefused
+			}
+			return droppingConn{testScriptedConn(time.Now(), 0)}, nil
+		},
+	}
+
+	var buf logBuffer
+	f.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	ch := make(chan *StreamOrderBook, 10)
+	err := f.Book(context.Background(), "A", ch)
+	require.ErrorIs(t, err, errRefused)
+	// Each connection delivered its snapshot and deltas, and failed
+	// reconnections are retried up to MaxReconnects times.
+	require.Len(t, ch, 6)
+	require.Equal(t, 3, dials)
+	require.Equal(t, []string{"A"}, observer.reconnected)
+
+	var logged []string
+	for _, record := range buf.records(t) {
+		msg := record["msg"].(string)
+		if attempt, ok := record["attempt"]; ok {
+			msg += fmt.Sprint(" ", attempt)
+		}
+		logged = append(logged, msg)
+	}
+	require.Equal(t, []string{
+		"subscribed",
+		"feed reconnecting 1", "feed reconnected 1",
+		"subscribed",
+		"feed reconnecting 1", "feed reconnecting 2",
+		"subscription ended",
+	}, logged)
+}
@@ -0,0 +1,231 @@
+package kalshi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// feedRecord is a message received by a Feed, as recorded: one JSON object
+// per line.
+type feedRecord struct {
+	ReceivedAt time.Time       `json:"t"`
+	Message    json.RawMessage `json:"m"`
+}
+
+// maxRecordSize bounds the size of a recorded message when replayed.
+const maxRecordSize = 16 << 20
+
+// FeedRecorder records the raw messages received by feeds, with their
+// receive times, as gzipped JSON lines. Every message is flushed as it is
+// recorded, so a recording survives a crash up to the last message, and
+// replays as if it ended there. It is safe for concurrent use.
+type FeedRecorder struct {
+	mu     sync.Mutex
+	gz     *gzip.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewFeedRecorder creates a FeedRecorder writing to w.
+func NewFeedRecorder(w io.Writer) *FeedRecorder {
+	return &FeedRecorder{gz: gzip.NewWriter(w)}
+}
+
+// OpenFeedRecorder creates a FeedRecorder writing to a new file at path. It
+// fails if the file exists: a recording cut short by a crash can't be
+// appended to, so each session should record to its own file, e.g. named
+// after its start time.
+func OpenFeedRecorder(path string) (*FeedRecorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("os.OpenFile: %w", err)
+	}
+	r := NewFeedRecorder(f)
+	r.closer = f
+	return r, nil
+}
+
+func (r *FeedRecorder) record(message []byte, receivedAt time.Time) error {
+	b, err := json.Marshal(feedRecord{ReceivedAt: receivedAt, Message: message})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if _, err := r.gz.Write(append(b, '\n')); err != nil {
+		r.err = fmt.Errorf("gzip.Write: %w", err)
+		return r.err
+	}
+	if err := r.gz.Flush(); err != nil {
+		r.err = fmt.Errorf("gzip.Flush: %w", err)
+		return r.err
+	}
+	return nil
+}
+
+// Close completes the recording, and closes its file if it was opened by
+// OpenFeedRecorder.
+func (r *FeedRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.gz.Close()
+	if r.closer != nil {
+		err = errors.Join(err, r.closer.Close())
+	}
+	return err
+}
+
+// Record makes the Feed record every message it receives to r. Messages
+// that fail to be recorded fail Book, so that recordings have no gaps.
+func (s *Feed) Record(r *FeedRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = r
+	s.c = recordingConn{feedConn: s.c, recorder: r}
+}
+
+type recordingConn struct {
+	feedConn
+	recorder *FeedRecorder
+}
+
+func (c recordingConn) Read(ctx context.Context) ([]byte, time.Time, error) {
+	message, receivedAt, err := c.feedConn.Read(ctx)
+	if err != nil {
+		return message, receivedAt, err
+	}
+	if err := c.recorder.record(message, receivedAt); err != nil {
+		return nil, receivedAt, fmt.Errorf("record: %w", err)
+	}
+	return message, receivedAt, nil
+}
+
+// ReplayFeed creates a Feed replaying a recording made by a FeedRecorder.
+// Messages go through the same decoding as live ones, with their recorded
+// receive times, so Book produces the same StreamOrderBooks, and then
+// returns an error wrapping io.EOF. A recording cut short, e.g. by a crash,
+// ends after its last complete message. Commands sent to the Feed are
+// ignored.
+//
+// speed scales the pace of the replay: 1 replays in real time, 10 ten times
+// faster, and 0 as fast as possible.
+func ReplayFeed(r io.Reader, speed float64) (*Feed, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("gzip.NewReader: %w", err)
+	}
+	scanner := bufio.NewScanner(truncatedReader{gz})
+	scanner.Buffer(nil, maxRecordSize)
+	scanner.Split(scanRecords)
+	return &Feed{c: &replayConn{
+		gz:      gz,
+		scanner: scanner,
+		speed:   speed,
+	}}, nil
+}
+
+// OpenFeedReplay creates a Feed replaying the recording in the file at
+// path, as ReplayFeed. Closing the Feed closes the file.
+func OpenFeedReplay(path string, speed float64) (*Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	feed, err := ReplayFeed(f, speed)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	feed.c.(*replayConn).closer = f
+	return feed, nil
+}
+
+// truncatedReader reads a recording that may have been cut short, ending it
+// at the cut.
+type truncatedReader struct {
+	r io.Reader
+}
+
+func (r truncatedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// scanRecords splits a recording into records. A final line without a
+// newline is a record cut short and is dropped.
+func scanRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
+
+type replayConn struct {
+	gz      *gzip.Reader
+	scanner *bufio.Scanner
+	closer  io.Closer
+	speed   float64
+
+	// first and start are the receive time of the first message and when
+	// it was replayed, against which later messages are paced.
+	first time.Time
+	start time.Time
+}
+
+func (c *replayConn) Read(ctx context.Context) ([]byte, time.Time, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, time.Time{}, fmt.Errorf("scan: %w", err)
+		}
+		return nil, time.Time{}, io.EOF
+	}
+	var record feedRecord
+	if err := json.Unmarshal(c.scanner.Bytes(), &record); err != nil {
+		return nil, time.Time{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if c.start.IsZero() {
+		c.first, c.start = record.ReceivedAt, time.Now()
+	} else if c.speed > 0 {
+		due := c.start.Add(time.Duration(float64(record.ReceivedAt.Sub(c.first)) / c.speed))
+		if wait := time.Until(due); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, time.Time{}, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	}
+	return record.Message, record.ReceivedAt, nil
+}
+
+func (c *replayConn) Write(context.Context, any) error {
+	return nil
+}
+
+func (c *replayConn) Close() error {
+	err := c.gz.Close()
+	if c.closer != nil {
+		err = errors.Join(err, c.closer.Close())
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package kalshi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedConn plays messages as if received at the given times.
+type scriptedConn struct {
+	messages []string
+	times    []time.Time
+}
+
+func (c *scriptedConn) Read(context.Context) ([]byte, time.Time, error) {
+	if len(c.messages) == 0 {
+		return nil, time.Time{}, io.EOF
+	}
+	message, at := c.messages[0], c.times[0]
+	c.messages, c.times = c.messages[1:], c.times[1:]
+	return []byte(message), at, nil
+}
+
+func (c *scriptedConn) Write(context.Context, any) error { return nil }
+
+func (c *scriptedConn) Close() error { return nil }
+
+func testScriptedConn(start time.Time, gap time.Duration) *scriptedConn {
+	c := &scriptedConn{messages: []string{
+		`{"id": 1, "type": "subscribed", "msg": {"channel": "orderbook_delta", "sid": 7}}`,
+		`{"type": "orderbook_snapshot", "sid": 7, "seq": 1, "msg": {"market_id": "A", "yes": [[40, 10]], "no": [[55, 5]]}}`,
+		`{"type": "orderbook_delta", "sid": 7, "seq": 2, "msg": {"market_id": "A", "price": 41, "delta": 3, "side": "yes"}}`,
+		`{"type": "orderbook_delta", "sid": 7, "seq": 3, "msg": {"market_id": "A", "price": 40, "delta": -10, "side": "yes"}}`,
+	}}
+	for i := range c.messages {
+		c.times = append(c.times, start.Add(time.Duration(i)*gap))
+	}
+	return c
+}
+
+// collectBooks runs f.Book until the feed ends.
+func collectBooks(t *testing.T, f *Feed) []*StreamOrderBook {
+	ch := make(chan *StreamOrderBook, 10)
+	err := f.Book(context.Background(), "A", ch)
+	require.ErrorIs(t, err, io.EOF)
+	close(ch)
+	var books []*StreamOrderBook
+	for book := range ch {
+		books = append(books, book)
+	}
+	return books
+}
+
+func TestFeedRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	recorder := NewFeedRecorder(&buf)
+	live := &Feed{c: testScriptedConn(start, time.Second)}
+	live.Record(recorder)
+	want := collectBooks(t, live)
+	require.NoError(t, recorder.Close())
+
+	require.Len(t, want, 3)
+	require.Equal(t, start.Add(2*time.Second), want[1].LoadedAt)
+	require.Equal(t, OrderBookBids{{Price: 41, Quantity: 3}}, want[2].YesBids)
+
+	replay, err := ReplayFeed(bytes.NewReader(buf.Bytes()), 0)
+	require.NoError(t, err)
+	require.Equal(t, want, collectBooks(t, replay))
+	require.NoError(t, replay.Close())
+}
+
+func TestFeedReplaySpeed(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "feed.jsonl.gz")
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	recorder, err := OpenFeedRecorder(path)
+	require.NoError(t, err)
+	f := &Feed{c: testScriptedConn(start, 100*time.Millisecond)}
+	f.Record(recorder)
+	collectBooks(t, f)
+	require.NoError(t, recorder.Close())
+
+	// Recordings aren't appended to.
+	_, err = OpenFeedRecorder(path)
+	require.Error(t, err)
+
+	replay, err := OpenFeedReplay(path, 10)
+	require.NoError(t, err)
+	defer replay.Close()
+	began := time.Now()
+	require.Len(t, collectBooks(t, replay), 3)
+	// Three gaps of 100ms at ten times the speed.
+	require.GreaterOrEqual(t, time.Since(began), 30*time.Millisecond)
+}
+
+func TestFeedReplayTruncated(t *testing.T) {
+	t.Parallel()
+
+	// A recorder that is never closed, as if the process crashed.
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	live := &Feed{c: testScriptedConn(start, time.Second)}
+	live.Record(NewFeedRecorder(&buf))
+	want := collectBooks(t, live)
+
+	replay, err := ReplayFeed(bytes.NewReader(buf.Bytes()), 0)
+	require.NoError(t, err)
+	require.Equal(t, want, collectBooks(t, replay))
+
+	// Cut anywhere past the gzip header, the recording ends after its last
+	// complete message.
+	recording := buf.Bytes()
+	for n := 10; n < len(recording); n++ {
+		replay, err = ReplayFeed(bytes.NewReader(recording[:n]), 0)
+		require.NoError(t, err)
+		books := collectBooks(t, replay)
+		require.LessOrEqual(t, len(books), len(want))
+		for i, book := range books {
+			require.Equal(t, want[i], book, "cut at %d", n)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package kalshi
+
+import "strings"
+
+// FeeRate is a Kalshi trading fee rate in basis points.
+//
+// Kalshi charges rate × C × P × (1 − P) per order, where C is the number of
+// contracts and P the price in dollars, rounded up to the next cent. Fees are
+// described here: https://kalshi.com/docs/kalshi-fee-schedule.pdf.
+type FeeRate int
+
+const (
+	// DefaultTakerFeeRate is the general taker fee of 0.07.
+	DefaultTakerFeeRate FeeRate = 700
+	// DefaultMakerFeeRate is the maker fee of 0.0175 charged on markets
+	// with maker fees.
+	DefaultMakerFeeRate FeeRate = 175
+)
+
+// Fee returns the fee for trading count contracts at price.
+func (r FeeRate) Fee(price Cents, count int) Cents {
+	if r <= 0 || count <= 0 || price <= 0 || price >= 100 {
+		return 0
+	}
+	// In cents, the fee is rate/10000 × C × price × (100 − price)/100.
+	const denominator = 10000 * 100
+	numerator := int64(r) * int64(count) * int64(price) * int64(100-price)
+	return Cents((numerator + denominator - 1) / denominator)
+}
+
+// FeeRates are the taker and maker fee rates of a series.
+type FeeRates struct {
+	Taker FeeRate
+	Maker FeeRate
+}
+
+// Rate returns the taker or maker rate.
+func (r FeeRates) Rate(taker bool) FeeRate {
+	if taker {
+		return r.Taker
+	}
+	return r.Maker
+}
+
+// FeeSchedule determines the fee rates of markets.
+type FeeSchedule struct {
+	// Default applies to series without an override.
+	Default FeeRates
+	// Series overrides the rates by series ticker.
+	Series map[string]FeeRates
+}
+
+// DefaultFeeSchedule returns the general fee schedule: taker fees of 0.07,
+// no maker fees, and reduced taker fees on the S&P 500 and Nasdaq-100 series.
+func DefaultFeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		Default: FeeRates{Taker: DefaultTakerFeeRate},
+		Series: map[string]FeeRates{
+			"INX":        {Taker: 350},
+			"INXD":       {Taker: 350},
+			"NASDAQ100":  {Taker: 350},
+			"NASDAQ100D": {Taker: 350},
+		},
+	}
+}
+
+// Rates returns the fee rates for ticker, which may be a series, event or
+// market ticker.
+func (s FeeSchedule) Rates(ticker string) FeeRates {
+	if rates, ok := s.Series[seriesTickerOf(ticker)]; ok {
+		return rates
+	}
+	return s.Default
+}
+
+// Fee returns the fee for trading count contracts of ticker at price.
+func (s FeeSchedule) Fee(ticker string, price Cents, count int, taker bool) Cents {
+	return s.Rates(ticker).Rate(taker).Fee(price, count)
+}
+
+// seriesTickerOf returns the series of a series, event or market ticker.
+// Kalshi tickers are hyphen-separated with the series first, e.g.
+// KXBTC-25JAN01-T100000.
+func seriesTickerOf(ticker string) string {
+	series, _, _ := strings.Cut(ticker, "-")
+	return series
+}
+
+// BestYesOfferWithFee is like BestYesOffer but includes the taker fee in
+// the average price.
+func (b OrderBook) BestYesOfferWithFee(quantity int, rate FeeRate) (Cents, bool) {
+	return b.NoBids.bestPriceWithFee(quantity, rate)
+}
+
+// BestNoOfferWithFee is like BestNoOffer but includes the taker fee in the
+// average price.
+func (b OrderBook) BestNoOfferWithFee(quantity int, rate FeeRate) (Cents, bool) {
+	return b.YesBids.bestPriceWithFee(quantity, rate)
+}
+
+// bestPriceWithFee is like bestPrice but adds the fee charged at each price
+// level taken.
+func (b OrderBookBids) bestPriceWithFee(wantQuantity int, rate FeeRate) (Cents, bool) {
+	if wantQuantity <= 0 {
+		return -1, false
+	}
+
+	var (
+		foundQuantity int
+		total         Cents
+	)
+	for i := len(b) - 1; i >= 0 && foundQuantity < wantQuantity; i-- {
+		price := Cents(100) - b[i].Price
+		quantity := min(b[i].Quantity, wantQuantity-foundQuantity)
+
+		foundQuantity += quantity
+		total += price*Cents(quantity) + rate.Fee(price, quantity)
+	}
+	if foundQuantity < wantQuantity {
+		return -1, false
+	}
+	// We round up to be conservative.
+	return Cents(conservativeRound(float64(total) / float64(wantQuantity))), true
+}
+
+// MarketValueAfterFees is like MarketValue but subtracts the taker fee of
+// closing the position at the mid price.
+func (m *Market) MarketValueAfterFees(p *MarketPosition, rate FeeRate) Cents {
+	if p == nil {
+		return 0
+	}
+
+	mid := m.YesMidPrice()
+	if p.Position < 0 {
+		mid = m.NoMidPrice()
+	}
+	return m.MarketValue(p) - rate.Fee(mid, p.AbsPosition())
+}
+
+// EstimateReturnAfterFees is like EstimateReturn but also subtracts the
+// taker fee of closing the position at the mid price.
+func (m *Market) EstimateReturnAfterFees(p *MarketPosition, rate FeeRate) Cents {
+	if p == nil {
+		return 0
+	}
+	return m.EstimateReturn(p) - (m.MarketValue(p) - m.MarketValueAfterFees(p, rate))
+}
+
+// ExpectedValue returns the expected profit in cents of buying count
+// contracts of side at price, net of the fee at rate, given the probability
+// that the market resolves Yes.
+func ExpectedValue(side Side, price Cents, count int, yesProbability float64, rate FeeRate) float64 {
+	winProbability := yesProbability
+	if side == No {
+		winProbability = 1 - yesProbability
+	}
+	payout := winProbability * 100 * float64(count)
+	cost := float64(price)*float64(count) + float64(rate.Fee(price, count))
+	return payout - cost
+}
@@ -0,0 +1,81 @@
+package kalshi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeRate(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		rate  FeeRate
+		price Cents
+		count int
+		want  Cents
+	}{
+		{DefaultTakerFeeRate, 50, 100, 175},
+		// 0.07 × 0.01 × 0.99 = $0.000693, rounded up.
+		{DefaultTakerFeeRate, 1, 1, 1},
+		// $0.147 rounds up to 15 cents.
+		{DefaultTakerFeeRate, 30, 10, 15},
+		{DefaultMakerFeeRate, 50, 100, 44},
+		{350, 50, 100, 88},
+		{DefaultTakerFeeRate, 0, 100, 0},
+		{DefaultTakerFeeRate, 100, 100, 0},
+		{DefaultTakerFeeRate, 50, 0, 0},
+	} {
+		require.Equal(t, tt.want, tt.rate.Fee(tt.price, tt.count), "%d × %d at %d", tt.count, tt.price, tt.rate)
+	}
+}
+
+func TestFeeSchedule(t *testing.T) {
+	t.Parallel()
+
+	s := DefaultFeeSchedule()
+	require.Equal(t, Cents(175), s.Fee("KXBTC-25JAN01-T100000", 50, 100, true))
+	require.Equal(t, Cents(0), s.Fee("KXBTC-25JAN01-T100000", 50, 100, false))
+	require.Equal(t, Cents(88), s.Fee("INX-25JAN01-B5000", 50, 100, true))
+	require.Equal(t, FeeRates{Taker: 350}, s.Rates("NASDAQ100"))
+}
+
+func TestFeeAdjustedPrices(t *testing.T) {
+	t.Parallel()
+
+	book := OrderBook{
+		NoBids: OrderBookBids{
+			{40, 100},
+			{50, 100},
+		},
+	}
+
+	// Without fees the average is 50.
+	price, ok := book.BestYesOffer(100)
+	require.True(t, ok)
+	require.Equal(t, Cents(50), price)
+
+	price, ok = book.BestYesOfferWithFee(100, DefaultTakerFeeRate)
+	require.True(t, ok)
+	require.Equal(t, Cents(52), price)
+
+	// 100 at 50 plus 100 at 60, with fees of 175 and 168.
+	price, ok = book.BestYesOfferWithFee(200, DefaultTakerFeeRate)
+	require.True(t, ok)
+	require.Equal(t, Cents(57), price)
+
+	_, ok = book.BestYesOfferWithFee(201, DefaultTakerFeeRate)
+	require.False(t, ok)
+	_, ok = book.BestNoOfferWithFee(1, DefaultTakerFeeRate)
+	require.False(t, ok)
+
+	market := &Market{YesBid: 48, YesAsk: 52, NoBid: 48, NoAsk: 52}
+	position := &MarketPosition{Position: -100, MarketExposure: 4000}
+	require.Equal(t, Cents(5000), market.MarketValue(position))
+	require.Equal(t, Cents(5000-175), market.MarketValueAfterFees(position, DefaultTakerFeeRate))
+	require.Equal(t, Cents(1000-175), market.EstimateReturnAfterFees(position, DefaultTakerFeeRate))
+
+	require.InDelta(t, 0, ExpectedValue(Yes, 50, 1, 0.5, 0), 1e-9)
+	require.InDelta(t, -2, ExpectedValue(Yes, 50, 1, 0.5, DefaultTakerFeeRate), 1e-9)
+	require.InDelta(t, 20-2, ExpectedValue(No, 50, 1, 0.3, DefaultTakerFeeRate), 1e-9)
+}
@@ -0,0 +1,160 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// redactedKeys are the attribute keys whose values are never logged.
+var redactedKeys = map[string]bool{
+	"signature": true,
+	"key_id":    true,
+	"balance":   true,
+
+	strings.ToLower(HeaderAccessKey):       true,
+	strings.ToLower(HeaderAccessSignature): true,
+}
+
+// Redacted replaces the values of redacted attributes.
+const Redacted = "[REDACTED]"
+
+type redactingHandler struct {
+	slog.Handler
+}
+
+// NewRedactingHandler wraps h so that the values of attributes named
+// "signature", "key_id", "balance", or after the signing headers are
+// replaced with Redacted, including within groups. Keys are matched
+// case-insensitively. slog.LogValuer values are resolved before they are
+// redacted.
+func NewRedactingHandler(h slog.Handler) slog.Handler {
+	if _, ok := h.(redactingHandler); ok {
+		return h
+	}
+	return redactingHandler{h}
+}
+
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redact(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redact(a)
+	}
+	return redactingHandler{h.Handler.WithAttrs(redacted)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.Handler.WithGroup(name)}
+}
+
+func redact(a slog.Attr) slog.Attr {
+	// Values are resolved first so that LogValuers can't hide secrets.
+	a.Value = a.Value.Resolve()
+	if redactedKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = redact(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	}
+	return a
+}
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// SetLogger makes the Client, and the feeds it opens afterwards, log to l.
+// Its handler is wrapped with NewRedactingHandler. A nil l disables logging,
+// which is the default.
+func (c *Client) SetLogger(l *slog.Logger) {
+	c.logger = redactingLogger(l)
+}
+
+// SetLogger makes the Feed log to l, as Client.SetLogger.
+func (s *Feed) SetLogger(l *slog.Logger) {
+	s.logger = redactingLogger(l)
+}
+
+func redactingLogger(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return nil
+	}
+	return slog.New(NewRedactingHandler(l.Handler()))
+}
+
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+func (s *Feed) log() *slog.Logger {
+	if s.logger == nil {
+		return discardLogger
+	}
+	return s.logger
+}
+
+func requestAttrs(info RequestInfo) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("request", info.Name),
+		slog.String("method", info.Method),
+		slog.String("endpoint", info.Endpoint),
+	}
+	if info.Ticker != "" {
+		attrs = append(attrs, slog.String("ticker", info.Ticker))
+	}
+	if info.OrderID != "" {
+		attrs = append(attrs, slog.String("order_id", info.OrderID))
+	}
+	if info.Attempt > 1 {
+		attrs = append(attrs, slog.Int("attempt", info.Attempt))
+	}
+	return attrs
+}
+
+// logResponse logs the outcome of a request: successes at debug level,
+// rate limiting and retryable errors as warnings, and other errors as
+// errors.
+func (c *Client) logResponse(ctx context.Context, info ResponseInfo) {
+	logger := c.log()
+	attrs := requestAttrs(info.RequestInfo)
+
+	var httpErr *HttpError
+	switch {
+	case info.Err == nil:
+		attrs = append(attrs, slog.Int("status", info.StatusCode), slog.Duration("latency", info.Latency))
+		logger.LogAttrs(ctx, slog.LevelDebug, "request completed", attrs...)
+	case info.StatusCode == 0 && errors.Is(info.Err, ErrRateLimitExceeded):
+		logger.LogAttrs(ctx, slog.LevelWarn, "request rate limited", attrs...)
+	case errors.As(info.Err, &httpErr):
+		attrs = append(attrs,
+			slog.Int("status", info.StatusCode),
+			slog.Duration("latency", info.Latency),
+			slog.String("error_code", httpErr.ErrorCode),
+			slog.String("request_id", httpErr.RequestID),
+		)
+		level := slog.LevelError
+		if httpErr.IsRetryable() {
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "request failed", attrs...)
+	default:
+		attrs = append(attrs, slog.Duration("latency", info.Latency), slog.Any("error", info.Err))
+		logger.LogAttrs(ctx, slog.LevelError, "request failed", attrs...)
+	}
+}
@@ -0,0 +1,132 @@
+package kalshi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) records(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		delete(record, "time")
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRedactingHandler(t *testing.T) {
+	t.Parallel()
+
+	var buf logBuffer
+	logger := slog.New(NewRedactingHandler(slog.NewJSONHandler(&buf, nil)))
+	logger = slog.New(NewRedactingHandler(logger.Handler())).With("key_id", "abc")
+	logger.Info("hello",
+		slog.Int("Balance", 100),
+		slog.Group("headers", slog.String(HeaderAccessSignature, "sig"), slog.String("accept", "json")),
+		slog.String("ticker", "A"),
+		slog.Any("account", accountValuer{}),
+	)
+
+	require.Equal(t, []map[string]any{{
+		"level":   "INFO",
+		"msg":     "hello",
+		"key_id":  Redacted,
+		"Balance": Redacted,
+		"headers": map[string]any{HeaderAccessSignature: Redacted, "accept": "json"},
+		"ticker":  "A",
+		"account": map[string]any{"balance": Redacted, "member": "m"},
+	}}, buf.records(t))
+}
+
+// accountValuer hides a balance behind slog.LogValuer.
+type accountValuer struct{}
+
+func (accountValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("balance", 100), slog.String("member", "m"))
+}
+
+func TestClientLogging(t *testing.T) {
+	t.Parallel()
+
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/portfolio/balance":
+			writeJSON(t, w, http.StatusOK, map[string]int{"balance": 100})
+		default:
+			w.Header().Set("X-Request-Id", "req-1")
+			writeJSON(t, w, http.StatusNotFound, map[string]any{
+				"error": map[string]string{"code": "order_not_found", "message": "no such order"},
+			})
+		}
+	}))
+
+	// Nothing is logged without a logger.
+	ctx := context.Background()
+	_, err := client.GetBalance(ctx)
+	require.NoError(t, err)
+
+	var buf logBuffer
+	client.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	_, err = client.GetBalance(ctx)
+	require.NoError(t, err)
+	_, err = client.GetOrder(WithAttempt(ctx, 2), "missing")
+	require.ErrorIs(t, err, ErrOrderNotFound)
+	client.RateLimit = rate.NewLimiter(0, 0)
+	_, err = client.GetBalance(ctx)
+	require.ErrorIs(t, err, ErrRateLimitExceeded)
+
+	records := buf.records(t)
+	require.Len(t, records, 4)
+	for _, record := range records {
+		delete(record, "latency")
+	}
+	require.Equal(t, []map[string]any{
+		{
+			"level": "DEBUG", "msg": "request completed",
+			"request": "GetBalance", "method": "GET", "endpoint": "portfolio/balance",
+			"status": 200.0,
+		},
+		{
+			"level": "INFO", "msg": "retrying request",
+			"request": "GetOrder", "method": "GET", "endpoint": "portfolio/orders/missing",
+			"order_id": "missing", "attempt": 2.0,
+		},
+		{
+			"level": "ERROR", "msg": "request failed",
+			"request": "GetOrder", "method": "GET", "endpoint": "portfolio/orders/missing",
+			"order_id": "missing", "attempt": 2.0,
+			"status": 404.0, "error_code": "order_not_found", "request_id": "req-1",
+		},
+		{
+			"level": "WARN", "msg": "request rate limited",
+			"request": "GetBalance", "method": "GET", "endpoint": "portfolio/balance",
+		},
+	}, records)
+}
@@ -0,0 +1,295 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ClosedLot is a lot of contracts that was sold or settled.
+type ClosedLot struct {
+	Ticker string
+	Side   Side
+	Count  int
+
+	Acquired        time.Time
+	AcquiredTradeID string
+	Disposed        time.Time
+	// DisposedTradeID is empty if the lot was settled.
+	DisposedTradeID string
+
+	Proceeds Cents
+	Cost     Cents
+	// Fees are the fees of both the acquiring and the disposing trade
+	// allocated to the lot.
+	Fees Cents
+}
+
+// HoldingPeriod returns how long the lot was held.
+func (l ClosedLot) HoldingPeriod() time.Duration {
+	return l.Disposed.Sub(l.Acquired)
+}
+
+// Gain returns the proceeds net of the cost and fees.
+func (l ClosedLot) Gain() Cents {
+	return l.Proceeds - l.Cost - l.Fees
+}
+
+// LotTracker reconstructs lots from fills and settlements and records them
+// as they are closed. Fees are estimated with a FeeSchedule, as with
+// PnLEngine. Fills and settlements must be applied in chronological order.
+type LotTracker struct {
+	method CostBasisMethod
+	fees   FeeSchedule
+
+	mu     sync.Mutex
+	books  map[string]*lotBook
+	picks  map[string][]string
+	seen   map[string]struct{}
+	closed []ClosedLot
+}
+
+// NewLotTracker creates a LotTracker. AverageCost isn't meaningful for lots
+// and is treated as FIFO.
+func NewLotTracker(method CostBasisMethod, fees FeeSchedule) *LotTracker {
+	if method == AverageCost {
+		method = FIFO
+	}
+	return &LotTracker{
+		method: method,
+		fees:   fees,
+		books:  make(map[string]*lotBook),
+		picks:  make(map[string][]string),
+		seen:   make(map[string]struct{}),
+	}
+}
+
+// Identify selects the lots closed by a trade under SpecificID, by the IDs of
+// the trades that acquired them. It must be called before the closing fill is
+// applied. Contracts not covered by the identified lots are closed FIFO.
+func (t *LotTracker) Identify(closingTradeID string, lotTradeIDs ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.picks[closingTradeID] = lotTradeIDs
+}
+
+// ApplyFill books a fill. Fills are de-duplicated by TradeID and OrderID.
+func (t *LotTracker) ApplyFill(f Fill) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := f.TradeID + "/" + f.OrderID
+	if _, ok := t.seen[key]; ok {
+		return
+	}
+	t.seen[key] = struct{}{}
+
+	side, price := acquiredSide(f)
+	fee := t.fees.Fee(f.Ticker, f.YesPrice, f.Count, f.IsTaker)
+	closed := t.book(f.Ticker).acquire(side, price, f.Count, fee, f.CreatedTime, f.TradeID, t.picks[f.TradeID])
+	t.record(f.Ticker, f.TradeID, closed)
+}
+
+// ApplySettlement closes every open lot of the settled market.
+func (t *LotTracker) ApplySettlement(s Settlement) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(s.Ticker, "", t.book(s.Ticker).settle(s.MarketResult, s.SettledTime))
+}
+
+// Load applies the account's fills and settlements in chronological order.
+// Lots opened before the earliest fill returned by the API can't be
+// reconstructed.
+func (t *LotTracker) Load(ctx context.Context, client KalshiClientLogic) error {
+	type event struct {
+		at         time.Time
+		fill       *Fill
+		settlement *Settlement
+	}
+	var events []event
+
+	var fillsReq FillsRequest
+	for {
+		resp, err := client.GetFills(ctx, fillsReq)
+		if err != nil {
+			return fmt.Errorf("client.GetFills: %w", err)
+		}
+		for i := range resp.Fills {
+			events = append(events, event{at: resp.Fills[i].CreatedTime, fill: &resp.Fills[i]})
+		}
+		if resp.Cursor == "" {
+			break
+		}
+		fillsReq.Cursor = resp.Cursor
+	}
+
+	var settlementsReq SettlementsRequest
+	for {
+		resp, err := client.GetSettlements(ctx, settlementsReq)
+		if err != nil {
+			return fmt.Errorf("client.GetSettlements: %w", err)
+		}
+		for i := range resp.Settlements {
+			events = append(events, event{at: resp.Settlements[i].SettledTime, settlement: &resp.Settlements[i]})
+		}
+		if resp.Cursor == "" {
+			break
+		}
+		settlementsReq.Cursor = resp.Cursor
+	}
+
+	// Settlements come after any fills at the same time.
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].fill != nil && events[j].settlement != nil
+	})
+	for _, e := range events {
+		if e.fill != nil {
+			t.ApplyFill(*e.fill)
+		} else {
+			t.ApplySettlement(*e.settlement)
+		}
+	}
+	return nil
+}
+
+// ClosedLots returns the lots closed so far, in the order they were closed.
+func (t *LotTracker) ClosedLots() []ClosedLot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ClosedLot(nil), t.closed...)
+}
+
+func (t *LotTracker) book(ticker string) *lotBook {
+	b, ok := t.books[ticker]
+	if !ok {
+		b = &lotBook{method: t.method}
+		t.books[ticker] = b
+	}
+	return b
+}
+
+func (t *LotTracker) record(ticker, tradeID string, closed []closedLot) {
+	for _, c := range closed {
+		t.closed = append(t.closed, ClosedLot{
+			Ticker:          ticker,
+			Side:            c.Side,
+			Count:           c.Count,
+			Acquired:        c.Acquired,
+			AcquiredTradeID: c.TradeID,
+			Disposed:        c.Disposed,
+			DisposedTradeID: tradeID,
+			Proceeds:        c.Proceeds,
+			Cost:            c.Cost,
+			Fees:            c.Fee + c.DisposalFee,
+		})
+	}
+}
+
+// WriteLotsCSV writes lots as CSV with a header row. Amounts are in dollars
+// and times in RFC 3339.
+func WriteLotsCSV(w io.Writer, lots []ClosedLot) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"ticker", "side", "count",
+		"acquired", "acquired_trade_id", "disposed", "disposed_trade_id",
+		"holding_period_days", "proceeds", "cost", "fees", "gain",
+	}); err != nil {
+		return fmt.Errorf("cw.Write: %w", err)
+	}
+	for _, l := range lots {
+		if err := cw.Write([]string{
+			l.Ticker,
+			string(l.Side),
+			strconv.Itoa(l.Count),
+			l.Acquired.Format(time.RFC3339),
+			l.AcquiredTradeID,
+			l.Disposed.Format(time.RFC3339),
+			l.DisposedTradeID,
+			strconv.Itoa(int(l.HoldingPeriod() / (24 * time.Hour))),
+			formatDollars(l.Proceeds),
+			formatDollars(l.Cost),
+			formatDollars(l.Fees),
+			formatDollars(l.Gain()),
+		}); err != nil {
+			return fmt.Errorf("cw.Write: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("cw.Flush: %w", err)
+	}
+	return nil
+}
+
+// LedgerAccounts names the accounts used by WriteLedger.
+type LedgerAccounts struct {
+	// Cash receives the proceeds net of fees.
+	Cash string
+	// Positions holds the cost basis. The market ticker is appended as a
+	// sub-account.
+	Positions string
+	// Gains is credited with the gain.
+	Gains string
+}
+
+// DefaultLedgerAccounts returns the accounts used by WriteLedger when none
+// are given.
+func DefaultLedgerAccounts() LedgerAccounts {
+	return LedgerAccounts{
+		Cash:      "Assets:Kalshi:Cash",
+		Positions: "Assets:Kalshi:Positions",
+		Gains:     "Income:Kalshi:Gains",
+	}
+}
+
+// WriteLedger writes one balanced plain-text double-entry transaction per
+// lot, readable by ledger and hledger. A zero accounts uses
+// DefaultLedgerAccounts.
+func WriteLedger(w io.Writer, lots []ClosedLot, accounts LedgerAccounts) error {
+	if accounts == (LedgerAccounts{}) {
+		accounts = DefaultLedgerAccounts()
+	}
+	for _, l := range lots {
+		// Fees are allocated to lots as a whole, so the split between the
+		// acquiring and disposing trade isn't kept. The gain is the same
+		// either way, so all fees are charged against the proceeds.
+		cash := l.Proceeds - l.Fees
+		action := "Sell"
+		if l.DisposedTradeID == "" {
+			action = "Settle"
+		}
+		if _, err := fmt.Fprintf(w,
+			"%s %s %d %s %s\n"+
+				"    ; acquired: %s\n"+
+				"    %-50s %12s\n"+
+				"    %-50s %12s\n"+
+				"    %-50s %12s\n\n",
+			l.Disposed.Format("2006/01/02"), action, l.Count, l.Ticker, l.Side,
+			l.Acquired.Format("2006/01/02"),
+			accounts.Cash, formatDollars(cash),
+			accounts.Positions+":"+l.Ticker, formatDollars(-l.Cost),
+			accounts.Gains, formatDollars(-l.Gain()),
+		); err != nil {
+			return fmt.Errorf("fmt.Fprintf: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatDollars formats c as a dollar amount without rounding errors.
+func formatDollars(c Cents) string {
+	sign := ""
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, c/100, c%100)
+}
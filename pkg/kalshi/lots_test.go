@@ -0,0 +1,184 @@
+package kalshi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLotTrackerMethods(t *testing.T) {
+	t.Parallel()
+
+	const ticker = "KXBTC-25JAN01-T100000"
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		method       CostBasisMethod
+		wantAcquired string
+		wantCost     Cents
+	}{
+		{FIFO, "a", 200},
+		{LIFO, "c", 300},
+		{SpecificID, "b", 250},
+	} {
+		lt := NewLotTracker(tt.method, FeeSchedule{})
+		lt.Identify("sell", "b")
+		lt.ApplyFill(testFill("a", ticker, Yes, Buy, 40, 10, start))
+		lt.ApplyFill(testFill("b", ticker, Yes, Buy, 50, 10, start.Add(time.Hour)))
+		lt.ApplyFill(testFill("c", ticker, Yes, Buy, 60, 10, start.Add(2*time.Hour)))
+		lt.ApplyFill(testFill("sell", ticker, Yes, Sell, 70, 5, start.Add(48*time.Hour)))
+
+		lots := lt.ClosedLots()
+		require.Len(t, lots, 1, tt.method)
+		require.Equal(t, ClosedLot{
+			Ticker:          ticker,
+			Side:            Yes,
+			Count:           5,
+			Acquired:        lots[0].Acquired,
+			AcquiredTradeID: tt.wantAcquired,
+			Disposed:        start.Add(48 * time.Hour),
+			DisposedTradeID: "sell",
+			Proceeds:        350,
+			Cost:            tt.wantCost,
+		}, lots[0], tt.method)
+	}
+}
+
+func TestLotTrackerSettlement(t *testing.T) {
+	t.Parallel()
+
+	const ticker = "KXBTC-25JAN01-T100000"
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lt := NewLotTracker(FIFO, DefaultFeeSchedule())
+	buy := testFill("a", ticker, No, Buy, 50, 100, start)
+	buy.IsTaker = true
+	lt.ApplyFill(buy)
+	lt.ApplyFill(testFill("b", ticker, Yes, Sell, 50, 100, start.Add(time.Hour)))
+	// Buying Yes closes half of the No lots.
+	lt.ApplyFill(testFill("c", ticker, Yes, Buy, 40, 100, start.Add(2*time.Hour)))
+	lt.ApplySettlement(Settlement{Ticker: ticker, MarketResult: "no", SettledTime: start.Add(72 * time.Hour)})
+
+	lots := lt.ClosedLots()
+	require.Len(t, lots, 2)
+
+	require.Equal(t, "c", lots[0].DisposedTradeID)
+	require.Equal(t, 100, lots[0].Count)
+	require.Equal(t, Cents(6000), lots[0].Proceeds)
+	require.Equal(t, Cents(5000), lots[0].Cost)
+	require.Equal(t, Cents(175), lots[0].Fees)
+	require.Equal(t, Cents(825), lots[0].Gain())
+
+	require.Empty(t, lots[1].DisposedTradeID)
+	require.Equal(t, "b", lots[1].AcquiredTradeID)
+	require.Equal(t, Cents(10000), lots[1].Proceeds)
+	require.Equal(t, 71*time.Hour, lots[1].HoldingPeriod())
+}
+
+type lotsClient struct {
+	KalshiClientLogic
+	fills       []Fill
+	settlements []Settlement
+}
+
+func (c *lotsClient) GetFills(_ context.Context, req FillsRequest) (*FillsResponse, error) {
+	// One fill per page, newest first.
+	i := 0
+	if req.Cursor != "" {
+		i = int(req.Cursor[0] - '0')
+	}
+	resp := &FillsResponse{Fills: []Fill{c.fills[len(c.fills)-1-i]}}
+	if i+1 < len(c.fills) {
+		resp.Cursor = string(rune('0' + i + 1))
+	}
+	return resp, nil
+}
+
+func (c *lotsClient) GetSettlements(context.Context, SettlementsRequest) (*SettlementsResponse, error) {
+	return &SettlementsResponse{Settlements: c.settlements}, nil
+}
+
+func TestLotTrackerLoad(t *testing.T) {
+	t.Parallel()
+
+	const ticker = "KXBTC-25JAN01-T100000"
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client := &lotsClient{
+		fills: []Fill{
+			testFill("a", ticker, Yes, Buy, 40, 10, start),
+			testFill("b", ticker, Yes, Sell, 50, 4, start.Add(time.Hour)),
+			testFill("c", ticker, Yes, Buy, 45, 2, start.Add(3*time.Hour)),
+		},
+		settlements: []Settlement{
+			{Ticker: ticker, MarketResult: "yes", SettledTime: start.Add(3 * time.Hour)},
+		},
+	}
+	lt := NewLotTracker(FIFO, FeeSchedule{})
+	require.NoError(t, lt.Load(context.Background(), client))
+
+	lots := lt.ClosedLots()
+	require.Len(t, lots, 3)
+	require.Equal(t, "b", lots[0].DisposedTradeID)
+	require.Equal(t, 4, lots[0].Count)
+	require.Equal(t, 6, lots[1].Count)
+	require.Equal(t, "a", lots[1].AcquiredTradeID)
+	require.Equal(t, "c", lots[2].AcquiredTradeID)
+}
+
+func TestWriteLots(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lots := []ClosedLot{
+		{
+			Ticker:          "KXBTC-25JAN01-T100000",
+			Side:            Yes,
+			Count:           10,
+			Acquired:        start,
+			AcquiredTradeID: "a",
+			Disposed:        start.Add(50 * time.Hour),
+			DisposedTradeID: "b",
+			Proceeds:        600,
+			Cost:            400,
+			Fees:            35,
+		},
+		{
+			Ticker:          "KXBTC-25JAN01-T90000",
+			Side:            No,
+			Count:           3,
+			Acquired:        start,
+			AcquiredTradeID: "c",
+			Disposed:        start.Add(time.Hour),
+			Cost:            150,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteLotsCSV(&buf, lots))
+	require.Equal(t, ""+
+		"ticker,side,count,acquired,acquired_trade_id,disposed,disposed_trade_id,holding_period_days,proceeds,cost,fees,gain\n"+
+		"KXBTC-25JAN01-T100000,yes,10,2025-01-01T00:00:00Z,a,2025-01-03T02:00:00Z,b,2,$6.00,$4.00,$0.35,$1.65\n"+
+		"KXBTC-25JAN01-T90000,no,3,2025-01-01T00:00:00Z,c,2025-01-01T01:00:00Z,,0,$0.00,$1.50,$0.00,-$1.50\n",
+		buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteLedger(&buf, lots, LedgerAccounts{}))
+	require.Equal(t, ""+
+		"2025/01/03 Sell 10 KXBTC-25JAN01-T100000 yes\n"+
+		"    ; acquired: 2025/01/01\n"+
+		"    Assets:Kalshi:Cash                                        $5.65\n"+
+		"    Assets:Kalshi:Positions:KXBTC-25JAN01-T100000            -$4.00\n"+
+		"    Income:Kalshi:Gains                                      -$1.65\n"+
+		"\n"+
+		"2025/01/01 Settle 3 KXBTC-25JAN01-T90000 no\n"+
+		"    ; acquired: 2025/01/01\n"+
+		"    Assets:Kalshi:Cash                                        $0.00\n"+
+		"    Assets:Kalshi:Positions:KXBTC-25JAN01-T90000             -$1.50\n"+
+		"    Income:Kalshi:Gains                                       $1.50\n"+
+		"\n",
+		buf.String())
+}
@@ -41,6 +41,7 @@ func (c *Client) Events(ctx context.Context, req EventsRequest) (*EventsResponse
 	var resp EventsResponse
 
 	err := c.request(ctx, request{
+		Name:         "Events",
 		Method:       "GET",
 		Endpoint:     "events",
 		QueryParams:  req,
@@ -67,6 +68,7 @@ func (c *Client) Event(ctx context.Context, event string) (*EventResponse, error
 	var resp EventResponse
 
 	err := c.request(ctx, request{
+		Name:         "Event",
 		Method:       "GET",
 		Endpoint:     "events/" + event,
 		JSONResponse: &resp,
@@ -169,6 +171,7 @@ func (c *Client) Markets(
 	var resp MarketsResponse
 
 	if err := c.request(ctx, request{
+		Name:         "Markets",
 		Method:       "GET",
 		Endpoint:     "markets",
 		QueryParams:  req,
@@ -218,6 +221,8 @@ func (c *Client) GetTrades(
 	var resp TradesResponse
 
 	if err := c.request(ctx, request{
+		Name:         "GetTrades",
+		Ticker:       req.Ticker,
 		Method:       "GET",
 		Endpoint:     "markets/trades",
 		QueryParams:  req,
@@ -236,6 +241,8 @@ func (c *Client) Market(ctx context.Context, ticker string) (*Market, error) {
 		Market Market `json:"market"`
 	}
 	err := c.request(ctx, request{
+		Name:         "Market",
+		Ticker:       ticker,
 		Method:       "GET",
 		Endpoint:     fmt.Sprintf("markets/%s", ticker),
 		JSONResponse: &resp,
@@ -271,8 +278,8 @@ type MarketHistoryResponse struct {
 // https://trading-api.readme.io/reference/getmarkethistory.
 type MarketHistoryRequest struct {
 	CursorRequest
-	MinTS Timestamp `json:"min_ts,omitempty"`
-	MaxTS Timestamp `json:"max_ts,omitempty"`
+	MinTS Timestamp `url:"min_ts,omitempty"`
+	MaxTS Timestamp `url:"max_ts,omitempty"`
 }
 
 func (c *Client) MarketHistory(
@@ -283,6 +290,8 @@ func (c *Client) MarketHistory(
 	var resp MarketHistoryResponse
 
 	err := c.request(ctx, request{
+		Name:         "MarketHistory",
+		Ticker:       ticker,
 		Method:       "GET",
 		Endpoint:     fmt.Sprintf("markets/%s/history", ticker),
 		QueryParams:  req,
@@ -295,6 +304,96 @@ func (c *Client) MarketHistory(
 	return &resp, nil
 }
 
+// CandlestickPeriod is the length of a candlestick in minutes.
+type CandlestickPeriod int
+
+const (
+	CandlestickMinute CandlestickPeriod = 1
+	CandlestickHour   CandlestickPeriod = 60
+	CandlestickDay    CandlestickPeriod = 1440
+)
+
+// Duration returns the length of the period.
+func (p CandlestickPeriod) Duration() time.Duration {
+	return time.Duration(p) * time.Minute
+}
+
+// OHLC is the open, high, low and close of a quote over a candlestick.
+type OHLC struct {
+	Open  Cents `json:"open"`
+	High  Cents `json:"high"`
+	Low   Cents `json:"low"`
+	Close Cents `json:"close"`
+}
+
+// TradePriceOHLC is the traded Yes price over a candlestick. Its fields are
+// nil if there were no trades during the candlestick.
+type TradePriceOHLC struct {
+	Open  *Cents `json:"open"`
+	High  *Cents `json:"high"`
+	Low   *Cents `json:"low"`
+	Close *Cents `json:"close"`
+	Mean  *Cents `json:"mean"`
+	// Previous is the close of the last candlestick with trades.
+	Previous *Cents `json:"previous"`
+}
+
+// Candlestick is described here:
+// https://trading-api.readme.io/reference/getmarketcandlesticks.
+type Candlestick struct {
+	// EndPeriodTS is the end of the candlestick, which starts one period
+	// earlier.
+	EndPeriodTS  Timestamp      `json:"end_period_ts"`
+	YesBid       OHLC           `json:"yes_bid"`
+	YesAsk       OHLC           `json:"yes_ask"`
+	Price        TradePriceOHLC `json:"price"`
+	Volume       int            `json:"volume"`
+	OpenInterest int            `json:"open_interest"`
+}
+
+// MarketCandlesticksRequest is described here:
+// https://trading-api.readme.io/reference/getmarketcandlesticks.
+type MarketCandlesticksRequest struct {
+	StartTS        Timestamp         `url:"start_ts"`
+	EndTS          Timestamp         `url:"end_ts"`
+	PeriodInterval CandlestickPeriod `url:"period_interval"`
+}
+
+// MarketCandlesticksResponse is described here:
+// https://trading-api.readme.io/reference/getmarketcandlesticks.
+type MarketCandlesticksResponse struct {
+	Ticker       string        `json:"ticker"`
+	Candlesticks []Candlestick `json:"candlesticks"`
+}
+
+// MarketCandlesticks is described here:
+// https://trading-api.readme.io/reference/getmarketcandlesticks.
+// If seriesTicker is empty, it is derived from ticker.
+func (c *Client) MarketCandlesticks(
+	ctx context.Context,
+	seriesTicker, ticker string,
+	req MarketCandlesticksRequest,
+) (*MarketCandlesticksResponse, error) {
+	if seriesTicker == "" {
+		seriesTicker = seriesTickerOf(ticker)
+	}
+
+	var resp MarketCandlesticksResponse
+	err := c.request(ctx, request{
+		Name:         "MarketCandlesticks",
+		Ticker:       ticker,
+		Method:       "GET",
+		Endpoint:     fmt.Sprintf("series/%s/markets/%s/candlesticks", seriesTicker, ticker),
+		QueryParams:  req,
+		JSONResponse: &resp,
+	}, unauthenticated)
+	if err != nil {
+		return nil, fmt.Errorf("c.request: %w", err)
+	}
+
+	return &resp, nil
+}
+
 // MarketOrderBook is described here:
 // https://trading-api.readme.io/reference/getmarketorderbook.
 func (c *Client) MarketOrderBook(ctx context.Context, ticker string) (*OrderBook, error) {
@@ -302,6 +401,8 @@ func (c *Client) MarketOrderBook(ctx context.Context, ticker string) (*OrderBook
 		OrderBook OrderBook `json:"orderbook"`
 	}
 	err := c.request(ctx, request{
+		Name:         "MarketOrderBook",
+		Ticker:       ticker,
 		Method:       "GET",
 		Endpoint:     fmt.Sprintf("markets/%s/orderbook/?depth=100", ticker),
 		JSONResponse: &resp,
@@ -327,6 +428,7 @@ func (c *Client) Series(ctx context.Context, seriesTicker string) (*Series, erro
 		Series Series `json:"series"`
 	}
 	if err := c.request(ctx, request{
+		Name:         "Series",
 		Method:       "GET",
 		Endpoint:     fmt.Sprintf("series/%s", seriesTicker),
 		JSONResponse: &resp,
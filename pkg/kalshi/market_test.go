@@ -3,6 +3,9 @@ package kalshi
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -142,3 +145,64 @@ func TestIntegrationGetMarkets(t *testing.T) {
 		})
 	}
 }
+
+func TestMarketCandlesticks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/series/INX/markets/INX-24JAN02-B4800/candlesticks", r.URL.Path)
+		require.Equal(t, url.Values{
+			"start_ts":        {strconv.FormatInt(start.Unix(), 10)},
+			"end_ts":          {strconv.FormatInt(start.Add(time.Hour).Unix(), 10)},
+			"period_interval": {"60"},
+		}, r.URL.Query())
+		_, _ = w.Write([]byte(`{
+			"ticker": "INX-24JAN02-B4800",
+			"candlesticks": [{
+				"end_period_ts": ` + strconv.FormatInt(start.Add(time.Hour).Unix(), 10) + `,
+				"yes_bid": {"open": 40, "high": 45, "low": 38, "close": 44},
+				"yes_ask": {"open": 42, "high": 47, "low": 40, "close": 46},
+				"price": {"open": null, "high": null, "low": null, "close": null, "mean": null, "previous": 41},
+				"volume": 0,
+				"open_interest": 120
+			}]
+		}`))
+	}))
+
+	resp, err := client.MarketCandlesticks(context.Background(), "", "INX-24JAN02-B4800", MarketCandlesticksRequest{
+		StartTS:        Timestamp(start),
+		EndTS:          Timestamp(start.Add(time.Hour)),
+		PeriodInterval: CandlestickHour,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Candlesticks, 1)
+
+	c := resp.Candlesticks[0]
+	require.True(t, start.Add(CandlestickHour.Duration()).Equal(c.EndPeriodTS.Time()))
+	require.Equal(t, OHLC{Open: 40, High: 45, Low: 38, Close: 44}, c.YesBid)
+	require.Equal(t, Cents(46), c.YesAsk.Close)
+	require.Nil(t, c.Price.Close)
+	require.Equal(t, Cents(41), *c.Price.Previous)
+	require.Equal(t, 120, c.OpenInterest)
+}
+
+func TestMarketHistoryQuery(t *testing.T) {
+	t.Parallel()
+
+	minTS := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, url.Values{
+			"limit":  {"10"},
+			"cursor": {"abc"},
+			"min_ts": {strconv.FormatInt(minTS.Unix(), 10)},
+		}, r.URL.Query())
+		_, _ = w.Write([]byte(`{"ticker": "A", "history": []}`))
+	}))
+
+	_, err := client.MarketHistory(context.Background(), "A", MarketHistoryRequest{
+		CursorRequest: CursorRequest{Limit: 10, Cursor: "abc"},
+		MinTS:         Timestamp(minTS),
+	})
+	require.NoError(t, err)
+}
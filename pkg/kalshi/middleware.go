@@ -0,0 +1,106 @@
+package kalshi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RequestInfo describes a request made by Client.
+type RequestInfo struct {
+	// Name is the Client method making the request, e.g. "CreateOrder".
+	Name   string
+	Method string
+	// Endpoint is the path relative to BaseURL, e.g. "portfolio/orders".
+	Endpoint string
+	// Ticker and OrderID are set for requests concerning a single market or
+	// order.
+	Ticker  string
+	OrderID string
+	// Attempt is 1 unless the request retries an earlier one, as marked by
+	// WithAttempt.
+	Attempt int
+}
+
+// ResponseInfo describes the outcome of a request.
+type ResponseInfo struct {
+	RequestInfo
+	// StatusCode is zero if no response was received.
+	StatusCode int
+	Latency    time.Duration
+	// Err is nil on success. Error responses are decoded into *HttpError.
+	Err error
+}
+
+// Middleware hooks into every request made by Client, including the
+// websocket handshake of OpenFeed. Either hook may be nil.
+type Middleware struct {
+	// BeforeRequest is called with the signed request before it is sent.
+	// It may modify the request, e.g. to add headers, or return a new one,
+	// e.g. with a derived context. Returning nil keeps the request as is.
+	// Returning an error aborts the request.
+	BeforeRequest func(req *http.Request, info RequestInfo) (*http.Request, error)
+	// AfterResponse is called once a request completes or fails, with the
+	// request as returned by the last BeforeRequest to succeed. If a
+	// BeforeRequest aborts the request, the middleware after it are
+	// skipped. Requests rejected by the rate limiter skip BeforeRequest and
+	// are passed unsent.
+	AfterResponse func(req *http.Request, info ResponseInfo)
+}
+
+type attemptKey struct{}
+
+// WithAttempt marks requests made with ctx as the given attempt of a call.
+// The retries made by this package are marked automatically.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func attemptFrom(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// Use appends middleware to the chain. BeforeRequest hooks run in the order
+// they were added, and AfterResponse hooks in reverse, so that the first
+// middleware wraps all others. Use must not be called concurrently with
+// requests.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// beforeRequest runs the BeforeRequest hooks. It returns the request to send
+// and the number of middleware whose AfterResponse should be called.
+func (c *Client) beforeRequest(req *http.Request, info RequestInfo) (*http.Request, int, error) {
+	if info.Attempt > 1 {
+		c.log().LogAttrs(req.Context(), slog.LevelInfo, "retrying request", requestAttrs(info)...)
+	}
+	for i, m := range c.middleware {
+		if m.BeforeRequest == nil {
+			continue
+		}
+		next, err := m.BeforeRequest(req, info)
+		if err != nil {
+			return req, i + 1, err
+		}
+		if next != nil {
+			req = next
+		}
+	}
+	return req, len(c.middleware), nil
+}
+
+// afterResponse runs the AfterResponse hooks of the first n middleware and
+// logs the response. ctx is the context of the call, which the logging
+// doesn't take from req since hooks may replace it.
+func (c *Client) afterResponse(ctx context.Context, req *http.Request, n int, info ResponseInfo) {
+	for i := n - 1; i >= 0; i-- {
+		if m := c.middleware[i]; m.AfterResponse != nil {
+			m.AfterResponse(req, info)
+		}
+	}
+	c.logResponse(ctx, info)
+}
@@ -0,0 +1,157 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "first,second", r.Header.Get("X-Chain"))
+		switch r.URL.Path {
+		case "/portfolio/balance":
+			writeJSON(t, w, http.StatusOK, map[string]int{"balance": 100})
+		default:
+			writeJSON(t, w, http.StatusNotFound, map[string]any{
+				"error": map[string]string{"code": "order_not_found", "message": "no such order"},
+			})
+		}
+	}))
+
+	var (
+		mu    sync.Mutex
+		calls []string
+		infos []ResponseInfo
+	)
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call)
+	}
+	for _, name := range []string{"first", "second"} {
+		client.Use(Middleware{
+			BeforeRequest: func(req *http.Request, info RequestInfo) (*http.Request, error) {
+				// Requests are signed before the hooks run.
+				require.NotEmpty(t, req.Header.Get(HeaderAccessSignature))
+				chain := name
+				if prev := req.Header.Get("X-Chain"); prev != "" {
+					chain = prev + "," + name
+				}
+				req.Header.Set("X-Chain", chain)
+				record("before " + info.Name)
+				return req, nil
+			},
+			AfterResponse: func(req *http.Request, info ResponseInfo) {
+				record("after " + info.Name)
+				if name == "first" {
+					mu.Lock()
+					infos = append(infos, info)
+					mu.Unlock()
+				}
+			},
+		})
+	}
+
+	ctx := context.Background()
+	_, err := client.GetBalance(ctx)
+	require.NoError(t, err)
+	_, err = client.GetOrder(ctx, "missing")
+	require.ErrorIs(t, err, ErrOrderNotFound)
+
+	require.Equal(t, []string{
+		"before GetBalance", "before GetBalance", "after GetBalance", "after GetBalance",
+		"before GetOrder", "before GetOrder", "after GetOrder", "after GetOrder",
+	}, calls)
+	require.Len(t, infos, 2)
+	require.Equal(t, RequestInfo{Name: "GetBalance", Method: "GET", Endpoint: "portfolio/balance", Attempt: 1}, infos[0].RequestInfo)
+	require.Equal(t, http.StatusOK, infos[0].StatusCode)
+	require.Positive(t, infos[0].Latency)
+	require.NoError(t, infos[0].Err)
+	require.Equal(t, http.StatusNotFound, infos[1].StatusCode)
+	require.ErrorIs(t, infos[1].Err, ErrOrderNotFound)
+}
+
+func TestMiddlewareAbortAndRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var sent bool
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = true
+	}))
+
+	abort := errors.New("abort")
+	var last ResponseInfo
+	client.Use(Middleware{
+		BeforeRequest: func(req *http.Request, info RequestInfo) (*http.Request, error) {
+			return req, abort
+		},
+		AfterResponse: func(req *http.Request, info ResponseInfo) {
+			last = info
+		},
+	})
+
+	_, err := client.GetBalance(context.Background())
+	require.ErrorIs(t, err, abort)
+	require.False(t, sent)
+	require.ErrorIs(t, last.Err, abort)
+
+	client.RateLimit = rate.NewLimiter(0, 0)
+	_, err = client.GetBalance(context.Background())
+	require.ErrorIs(t, err, ErrRateLimitExceeded)
+	require.Equal(t, ResponseInfo{
+		RequestInfo: RequestInfo{Name: "GetBalance", Method: "GET", Endpoint: "portfolio/balance", Attempt: 1},
+		Err:         ErrRateLimitExceeded,
+	}, last)
+}
+
+func TestMiddlewareAbortWithoutRequest(t *testing.T) {
+	t.Parallel()
+
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent")
+	}))
+
+	abort := errors.New("abort")
+	var calls []string
+	after := func(name string) func(*http.Request, ResponseInfo) {
+		return func(req *http.Request, info ResponseInfo) {
+			// The request before the failed hook is passed on.
+			require.NotNil(t, req)
+			calls = append(calls, name)
+		}
+	}
+	client.Use(
+		Middleware{AfterResponse: after("outer")},
+		Middleware{
+			BeforeRequest: func(req *http.Request, info RequestInfo) (*http.Request, error) {
+				return nil, abort
+			},
+			AfterResponse: after("aborting"),
+		},
+		Middleware{
+			BeforeRequest: func(req *http.Request, info RequestInfo) (*http.Request, error) {
+				t.Error("hook after the aborting one called")
+				return req, nil
+			},
+			AfterResponse: after("inner"),
+		},
+	)
+
+	// The aborted request is logged too.
+	var buf logBuffer
+	client.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	_, err := client.GetBalance(context.Background())
+	require.ErrorIs(t, err, abort)
+	require.Equal(t, []string{"aborting", "outer"}, calls)
+	require.Len(t, buf.records(t), 1)
+}
@@ -109,6 +109,44 @@ func (b OrderBook) BestNoOffer(quantity int) (Cents, bool) {
 	return b.YesBids.bestPrice(quantity)
 }
 
+// BestYesBid returns the highest Yes bid.
+func (b OrderBook) BestYesBid() (Cents, bool) {
+	return b.YesBids.best()
+}
+
+// BestNoBid returns the highest No bid.
+func (b OrderBook) BestNoBid() (Cents, bool) {
+	return b.NoBids.best()
+}
+
+// BestYesAsk returns the lowest price at which Yes contracts can be bought,
+// which is the complement of the highest No bid.
+func (b OrderBook) BestYesAsk() (Cents, bool) {
+	bid, ok := b.NoBids.best()
+	return 100 - bid, ok
+}
+
+// BestNoAsk returns the lowest price at which No contracts can be bought,
+// which is the complement of the highest Yes bid.
+func (b OrderBook) BestNoAsk() (Cents, bool) {
+	bid, ok := b.YesBids.best()
+	return 100 - bid, ok
+}
+
+// best returns the highest price with a non-zero quantity.
+func (b OrderBookBids) best() (Cents, bool) {
+	var (
+		best  Cents
+		found bool
+	)
+	for _, bid := range b {
+		if bid.Quantity > 0 && (!found || bid.Price > best) {
+			best, found = bid.Price, true
+		}
+	}
+	return best, found
+}
+
 // bestPrice returns the best average asking price that a slice of bids
 // provides to the opposite side of the market.
 func (b OrderBookBids) bestPrice(wantQuantity int) (Cents, bool) {
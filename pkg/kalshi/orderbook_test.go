@@ -64,6 +64,20 @@ func TestOrderBook(t *testing.T) {
 
 	offers = book.YesOffersUnderLimit(Cents(0))
 	require.Equal(t, 0, offers)
+
+	bid, ok := book.BestYesBid()
+	require.True(t, ok)
+	require.Equal(t, Cents(3), bid)
+
+	ask, ok := book.BestNoAsk()
+	require.True(t, ok)
+	require.Equal(t, Cents(97), ask)
+
+	_, ok = book.BestNoBid()
+	require.False(t, ok)
+
+	_, ok = book.BestYesAsk()
+	require.False(t, ok)
 }
 
 func TestParseOrderBook(t *testing.T) {
@@ -0,0 +1,593 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderState is the lifecycle state of an order tracked by an OrderManager.
+//
+// Orders move forward through pending → resting → partially filled and end in
+// either executed or canceled. Updates that would move an order backwards,
+// e.g. a stale reconciliation after a fill, are ignored.
+type OrderState string
+
+const (
+	OrderStatePending         OrderState = "pending"
+	OrderStateResting         OrderState = "resting"
+	OrderStatePartiallyFilled OrderState = "partially_filled"
+	OrderStateExecuted        OrderState = "executed"
+	OrderStateCanceled        OrderState = "canceled"
+)
+
+// Terminal reports whether no further transitions are possible from s.
+func (s OrderState) Terminal() bool {
+	return s == OrderStateExecuted || s == OrderStateCanceled
+}
+
+func (s OrderState) rank() int {
+	switch s {
+	case OrderStatePending:
+		return 0
+	case OrderStateResting:
+		return 1
+	case OrderStatePartiallyFilled:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// advance returns the state an order in s should be in after observing next.
+func (s OrderState) advance(next OrderState) OrderState {
+	if s.Terminal() || next.rank() < s.rank() {
+		return s
+	}
+	return next
+}
+
+// TrackedOrder is an OrderManager's view of a single order.
+type TrackedOrder struct {
+	ClientOrderID string
+	// OrderID is empty until the exchange acknowledges the order.
+	OrderID string
+	Ticker  string
+	Side    Side
+	Action  OrderAction
+	Type    OrderType
+	Price   Cents
+	// Count is the quantity originally requested.
+	Count          int
+	FilledCount    int
+	RemainingCount int
+	State          OrderState
+	UpdatedAt      time.Time
+}
+
+// Open reports whether the order may still trade.
+func (o TrackedOrder) Open() bool {
+	return !o.State.Terminal()
+}
+
+// trackedOrder is the mutable state kept per order.
+type trackedOrder struct {
+	TrackedOrder
+	seq int
+	// fillCount is the sum of unique fills observed for the order, and
+	// tradeIDs are their TradeIDs, until the order is terminal and every
+	// fill has been observed.
+	fillCount int
+	tradeIDs  []string
+	// exchangeUpdated is the latest LastUpdateTime applied.
+	exchangeUpdated time.Time
+	// changed is set while the order is in OrderManager.changed.
+	changed bool
+}
+
+// OrderManager places orders and tracks their state locally.
+//
+// State is kept current from the responses to the manager's own calls, from
+// fills (either polled via PollFills or pushed via ApplyFill), and from
+// periodic reconciliation against GetOrders. Run does both on an interval.
+type OrderManager struct {
+	client    KalshiClientLogic
+	submitter *OrderSubmitter
+
+	mu        sync.Mutex
+	seq       int
+	orders    map[string]*trackedOrder // by ClientOrderID
+	byOrderID map[string]*trackedOrder
+	seenFills map[string]struct{} // by TradeID, of orders still open
+	fillsFrom time.Time
+	// changed are the orders whose state changed since the last call to
+	// takeChanged, in the order they first changed.
+	changed []*trackedOrder
+
+	now func() time.Time
+}
+
+// NewOrderManager creates an OrderManager that places orders through client.
+// Only fills created after NewOrderManager is called are polled.
+func NewOrderManager(client KalshiClientLogic) *OrderManager {
+	return NewOrderManagerWithSubmitter(client, NewOrderSubmitter(client, uuid.NewString()))
+}
+
+// NewOrderManagerWithSubmitter is like NewOrderManager but places orders
+// through submitter, e.g. one with a stable namespace.
+func NewOrderManagerWithSubmitter(client KalshiClientLogic, submitter *OrderSubmitter) *OrderManager {
+	return &OrderManager{
+		client:    client,
+		submitter: submitter,
+		orders:    make(map[string]*trackedOrder),
+		byOrderID: make(map[string]*trackedOrder),
+		seenFills: make(map[string]struct{}),
+		fillsFrom: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Submit places req and starts tracking it. A ClientOrderID is generated if
+// req doesn't have one.
+//
+// Orders are placed through an OrderSubmitter. If the order was not placed it
+// is not tracked. If the outcome is unknown, an *UnknownOrderOutcomeError is
+// returned and the order stays pending until Reconcile finds it.
+func (m *OrderManager) Submit(ctx context.Context, req CreateOrderRequest) (TrackedOrder, error) {
+	if req.ClientOrderID == "" {
+		req.ClientOrderID = m.submitter.NextClientOrderID()
+	}
+
+	m.mu.Lock()
+	if _, ok := m.orders[req.ClientOrderID]; ok {
+		m.mu.Unlock()
+		return TrackedOrder{}, fmt.Errorf("%w: %s", ErrDuplicateClientOrderID, req.ClientOrderID)
+	}
+	m.seq++
+	t := &trackedOrder{
+		TrackedOrder: TrackedOrder{
+			ClientOrderID:  req.ClientOrderID,
+			Ticker:         req.Ticker,
+			Side:           req.Side,
+			Action:         req.Action,
+			Type:           req.Type,
+			Price:          requestPrice(req),
+			Count:          req.Count,
+			RemainingCount: req.Count,
+			State:          OrderStatePending,
+			UpdatedAt:      m.now(),
+		},
+		seq: m.seq,
+	}
+	m.orders[req.ClientOrderID] = t
+	m.markChangedLocked(t)
+	m.mu.Unlock()
+
+	order, err := m.submitter.Submit(ctx, req)
+	if err != nil {
+		var unknownErr *UnknownOrderOutcomeError
+		if !errors.As(err, &unknownErr) {
+			m.mu.Lock()
+			delete(m.orders, req.ClientOrderID)
+			m.mu.Unlock()
+		}
+		return m.snapshot(req.ClientOrderID), fmt.Errorf("m.submitter.Submit: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.applyOrderLocked(t, order)
+	return t.TrackedOrder, nil
+}
+
+// Cancel cancels the order with the given ClientOrderID.
+func (m *OrderManager) Cancel(ctx context.Context, clientOrderID string) (TrackedOrder, error) {
+	orderID, err := m.orderID(clientOrderID)
+	if err != nil {
+		return TrackedOrder{}, err
+	}
+	order, err := m.client.CancelOrder(ctx, orderID)
+	if err != nil {
+		return m.snapshot(clientOrderID), fmt.Errorf("m.client.CancelOrder: %w", err)
+	}
+	return m.ApplyOrder(order), nil
+}
+
+// Decrease reduces the remaining quantity of the order with the given
+// ClientOrderID.
+func (m *OrderManager) Decrease(ctx context.Context, clientOrderID string, req DecreaseOrderRequest) (TrackedOrder, error) {
+	orderID, err := m.orderID(clientOrderID)
+	if err != nil {
+		return TrackedOrder{}, err
+	}
+	order, err := m.client.DecreaseOrder(ctx, orderID, req)
+	if err != nil {
+		return m.snapshot(clientOrderID), fmt.Errorf("m.client.DecreaseOrder: %w", err)
+	}
+	return m.ApplyOrder(order), nil
+}
+
+// AmendResult is the state of an order before and after an amendment.
+type AmendResult struct {
+	Old TrackedOrder
+	New TrackedOrder
+}
+
+// Amend changes the price and total quantity of the order with the given
+// ClientOrderID in place, keeping its side and action.
+func (m *OrderManager) Amend(ctx context.Context, clientOrderID string, price Cents, count int) (AmendResult, error) {
+	m.mu.Lock()
+	t, ok := m.orders[clientOrderID]
+	if !ok || t.OrderID == "" {
+		m.mu.Unlock()
+		return AmendResult{}, fmt.Errorf("%w: %s", ErrOrderNotTracked, clientOrderID)
+	}
+	old := t.TrackedOrder
+	m.mu.Unlock()
+
+	req := AmendOrderRequest{
+		Ticker:        old.Ticker,
+		Side:          old.Side,
+		Action:        old.Action,
+		Count:         count,
+		ClientOrderID: clientOrderID,
+	}
+	req.SetPrice(price)
+
+	resp, err := m.client.AmendOrder(ctx, old.OrderID, req)
+	if err != nil {
+		return AmendResult{Old: old, New: old}, fmt.Errorf("m.client.AmendOrder: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if resp.Order.OrderID != "" && resp.Order.OrderID != t.OrderID {
+		delete(m.byOrderID, t.OrderID)
+		t.OrderID = ""
+	}
+	t.Price = price
+	t.Count = count
+	m.markChangedLocked(t)
+	m.applyOrderLocked(t, &resp.Order)
+	return AmendResult{Old: old, New: t.TrackedOrder}, nil
+}
+
+// ApplyOrder updates the tracked state from an Order returned by the
+// exchange. Orders that aren't tracked are ignored.
+func (m *OrderManager) ApplyOrder(order *Order) TrackedOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.lookupLocked(order)
+	if t == nil {
+		return TrackedOrder{}
+	}
+	m.applyOrderLocked(t, order)
+	return t.TrackedOrder
+}
+
+// ApplyFill updates the tracked state from a fill, e.g. one received from a
+// feed. Duplicate fills and fills for untracked orders are ignored. It
+// reports whether the fill changed any state.
+func (m *OrderManager) ApplyFill(fill Fill) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.applyFillLocked(fill)
+}
+
+// PollFills fetches fills created since the last poll and applies them.
+func (m *OrderManager) PollFills(ctx context.Context) error {
+	_, err := m.PollNewFills(ctx)
+	return err
+}
+
+// PollNewFills is like PollFills but also returns the fills that changed
+// the state of tracked orders, in the order they were applied.
+func (m *OrderManager) PollNewFills(ctx context.Context) ([]Fill, error) {
+	m.mu.Lock()
+	from := m.fillsFrom
+	m.mu.Unlock()
+
+	req := FillsRequest{
+		MinTS: Timestamp(from),
+	}
+	var applied []Fill
+	for {
+		resp, err := m.client.GetFills(ctx, req)
+		if err != nil {
+			return applied, fmt.Errorf("m.client.GetFills: %w", err)
+		}
+
+		m.mu.Lock()
+		for _, fill := range resp.Fills {
+			if m.applyFillLocked(fill) {
+				applied = append(applied, fill)
+			}
+			// MinTS has second precision, so we keep polling from the
+			// start of the second. Repeats are dropped by TradeID.
+			if ts := fill.CreatedTime.Truncate(time.Second); ts.After(m.fillsFrom) {
+				m.fillsFrom = ts
+			}
+		}
+		m.mu.Unlock()
+
+		if resp.Cursor == "" {
+			return applied, nil
+		}
+		req.Cursor = resp.Cursor
+	}
+}
+
+// Reconcile refreshes every open order from the exchange. Orders are listed
+// per ticker via GetOrders; open orders that aren't listed are fetched
+// individually.
+func (m *OrderManager) Reconcile(ctx context.Context) error {
+	m.mu.Lock()
+	tickers := make(map[string]struct{})
+	for _, t := range m.orders {
+		if t.Open() {
+			tickers[t.Ticker] = struct{}{}
+		}
+	}
+	m.mu.Unlock()
+
+	seen := make(map[string]struct{})
+	for ticker := range tickers {
+		req := OrdersRequest{Ticker: ticker}
+		for {
+			resp, err := m.client.GetOrders(ctx, req)
+			if err != nil {
+				return fmt.Errorf("m.client.GetOrders: %w", err)
+			}
+			for i := range resp.Orders {
+				if t := m.ApplyOrder(&resp.Orders[i]); t.ClientOrderID != "" {
+					seen[t.ClientOrderID] = struct{}{}
+				}
+			}
+			if resp.Cursor == "" {
+				break
+			}
+			req.Cursor = resp.Cursor
+		}
+	}
+
+	m.mu.Lock()
+	var missing []string
+	for id, t := range m.orders {
+		if _, ok := seen[id]; !ok && t.Open() && t.OrderID != "" {
+			missing = append(missing, t.OrderID)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, orderID := range missing {
+		order, err := m.client.GetOrder(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("m.client.GetOrder: %w", err)
+		}
+		m.ApplyOrder(order)
+	}
+	return nil
+}
+
+// Sync polls fills and then reconciles open orders.
+func (m *OrderManager) Sync(ctx context.Context) error {
+	if err := m.PollFills(ctx); err != nil {
+		return err
+	}
+	return m.Reconcile(ctx)
+}
+
+// Run calls Sync every interval until ctx is done or Sync fails. Rate limit
+// errors are not considered failures.
+func (m *OrderManager) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := m.Sync(ctx); err != nil && !isRateLimited(err) {
+				return err
+			}
+		}
+	}
+}
+
+// Order returns the tracked order with the given ClientOrderID.
+func (m *OrderManager) Order(clientOrderID string) (TrackedOrder, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.orders[clientOrderID]
+	if !ok {
+		return TrackedOrder{}, false
+	}
+	return t.TrackedOrder, true
+}
+
+// OpenOrders returns the open orders for ticker and side in the order they
+// were submitted. An empty ticker or side matches all.
+func (m *OrderManager) OpenOrders(ticker string, side Side) []TrackedOrder {
+	return m.list(func(t *trackedOrder) bool {
+		return t.Open() && (ticker == "" || t.Ticker == ticker) && (side == "" || t.Side == side)
+	})
+}
+
+// Orders returns every tracked order, open or not, in the order they were
+// submitted.
+func (m *OrderManager) Orders() []TrackedOrder {
+	return m.list(func(*trackedOrder) bool { return true })
+}
+
+func (m *OrderManager) list(match func(t *trackedOrder) bool) []TrackedOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var matched []*trackedOrder
+	for _, t := range m.orders {
+		if match(t) {
+			matched = append(matched, t)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].seq < matched[j].seq
+	})
+	orders := make([]TrackedOrder, len(matched))
+	for i, t := range matched {
+		orders[i] = t.TrackedOrder
+	}
+	return orders
+}
+
+func (m *OrderManager) snapshot(clientOrderID string) TrackedOrder {
+	t, _ := m.Order(clientOrderID)
+	return t
+}
+
+func (m *OrderManager) orderID(clientOrderID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.orders[clientOrderID]
+	if !ok || t.OrderID == "" {
+		return "", fmt.Errorf("%w: %s", ErrOrderNotTracked, clientOrderID)
+	}
+	return t.OrderID, nil
+}
+
+func (m *OrderManager) lookupLocked(order *Order) *trackedOrder {
+	if t, ok := m.byOrderID[order.OrderID]; ok {
+		return t
+	}
+	if order.ClientOrderID == "" {
+		return nil
+	}
+	return m.orders[order.ClientOrderID]
+}
+
+// takeChanged returns the tracked orders whose state changed since its last
+// call, in the order they first changed.
+func (m *OrderManager) takeChanged() []TrackedOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var orders []TrackedOrder
+	for _, t := range m.changed {
+		t.changed = false
+		// Orders that failed to be placed are no longer tracked.
+		if m.orders[t.ClientOrderID] == t {
+			orders = append(orders, t.TrackedOrder)
+		}
+	}
+	m.changed = nil
+	return orders
+}
+
+func (m *OrderManager) markChangedLocked(t *trackedOrder) {
+	if !t.changed {
+		t.changed = true
+		m.changed = append(m.changed, t)
+	}
+}
+
+func (m *OrderManager) applyOrderLocked(t *trackedOrder, order *Order) {
+	before := t.TrackedOrder
+	if t.OrderID == "" && order.OrderID != "" {
+		t.OrderID = order.OrderID
+		m.byOrderID[order.OrderID] = t
+	}
+
+	// An update that predates fills or updates already applied can't
+	// raise the remaining count.
+	filled := order.TakerFillCount + order.MakerFillCount
+	stale := filled < t.FilledCount
+	if order.LastUpdateTime != nil {
+		stale = stale || order.LastUpdateTime.Before(t.exchangeUpdated)
+		if !stale {
+			t.exchangeUpdated = order.LastUpdateTime.Time
+		}
+	}
+	if stale {
+		t.RemainingCount = min(t.RemainingCount, order.RemainingCount)
+	} else {
+		t.RemainingCount = order.RemainingCount
+	}
+	t.FilledCount = max(t.FilledCount, filled)
+
+	var next OrderState
+	switch order.Status {
+	case Executed:
+		next = OrderStateExecuted
+	case Canceled:
+		next = OrderStateCanceled
+	case Resting:
+		next = OrderStateResting
+		if t.FilledCount > 0 {
+			next = OrderStatePartiallyFilled
+		}
+	default:
+		next = OrderStatePending
+	}
+	t.State = t.State.advance(next)
+	t.UpdatedAt = m.now()
+	if before.UpdatedAt = t.UpdatedAt; before != t.TrackedOrder {
+		m.markChangedLocked(t)
+	}
+	m.forgetFillsLocked(t)
+}
+
+// forgetFillsLocked drops the TradeIDs of a terminal order once all its
+// fills have been observed. Later fills of the order are duplicates.
+func (m *OrderManager) forgetFillsLocked(t *trackedOrder) {
+	if !t.State.Terminal() || t.fillCount < t.FilledCount {
+		return
+	}
+	for _, id := range t.tradeIDs {
+		delete(m.seenFills, id)
+	}
+	t.tradeIDs = nil
+}
+
+func (m *OrderManager) applyFillLocked(fill Fill) bool {
+	if _, ok := m.seenFills[fill.TradeID]; ok {
+		return false
+	}
+	t, ok := m.byOrderID[fill.OrderID]
+	if !ok || (t.State.Terminal() && t.fillCount >= t.FilledCount) {
+		return false
+	}
+	m.seenFills[fill.TradeID] = struct{}{}
+	t.tradeIDs = append(t.tradeIDs, fill.TradeID)
+
+	t.fillCount += fill.Count
+	if t.fillCount > t.FilledCount {
+		t.RemainingCount -= t.fillCount - t.FilledCount
+		if t.RemainingCount < 0 {
+			t.RemainingCount = 0
+		}
+		t.FilledCount = t.fillCount
+	}
+
+	next := OrderStatePartiallyFilled
+	if t.RemainingCount == 0 {
+		next = OrderStateExecuted
+	}
+	t.State = t.State.advance(next)
+	t.UpdatedAt = m.now()
+	m.markChangedLocked(t)
+	m.forgetFillsLocked(t)
+	return true
+}
+
+// requestPrice is like CreateOrderRequest.Price but doesn't panic.
+func requestPrice(req CreateOrderRequest) Cents {
+	if req.Side == No {
+		return req.NoPrice
+	}
+	return req.YesPrice
+}
@@ -0,0 +1,189 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderState(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, OrderStateResting, OrderStatePending.advance(OrderStateResting))
+	require.Equal(t, OrderStatePartiallyFilled, OrderStatePartiallyFilled.advance(OrderStateResting))
+	require.Equal(t, OrderStateCanceled, OrderStatePartiallyFilled.advance(OrderStateCanceled))
+	require.Equal(t, OrderStateExecuted, OrderStateExecuted.advance(OrderStateCanceled))
+	require.Equal(t, OrderStateCanceled, OrderStateCanceled.advance(OrderStatePending))
+}
+
+func TestOrderManager(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newFakeClient()
+	m := NewOrderManager(client)
+
+	limit := func(ticker string, side Side, count int) CreateOrderRequest {
+		req := CreateOrderRequest{
+			Action: Buy,
+			Count:  count,
+			Ticker: ticker,
+			Type:   LimitOrder,
+			Side:   side,
+		}
+		req.SetPrice(40)
+		return req
+	}
+
+	a, err := m.Submit(ctx, limit("A", Yes, 10))
+	require.NoError(t, err)
+	require.NotEmpty(t, a.ClientOrderID)
+	require.NotEmpty(t, a.OrderID)
+	require.Equal(t, OrderStateResting, a.State)
+	require.Equal(t, Cents(40), a.Price)
+
+	b, err := m.Submit(ctx, limit("A", No, 5))
+	require.NoError(t, err)
+	c, err := m.Submit(ctx, limit("B", Yes, 5))
+	require.NoError(t, err)
+
+	_, err = m.Submit(ctx, CreateOrderRequest{ClientOrderID: a.ClientOrderID})
+	require.ErrorIs(t, err, ErrDuplicateClientOrderID)
+
+	require.Len(t, m.OpenOrders("", ""), 3)
+	require.Equal(t, []TrackedOrder{a, b}, m.OpenOrders("A", ""))
+	require.Equal(t, []TrackedOrder{a}, m.OpenOrders("A", Yes))
+
+	t.Run("Fills", func(t *testing.T) {
+		fill := client.fill(a.OrderID, 4)
+		require.True(t, m.ApplyFill(fill))
+		require.False(t, m.ApplyFill(fill), "duplicate")
+
+		got, ok := m.Order(a.ClientOrderID)
+		require.True(t, ok)
+		require.Equal(t, OrderStatePartiallyFilled, got.State)
+		require.Equal(t, 4, got.FilledCount)
+		require.Equal(t, 6, got.RemainingCount)
+
+		// Polling sees the same fill again plus a new one.
+		client.fill(a.OrderID, 6)
+		require.NoError(t, m.PollFills(ctx))
+
+		got, _ = m.Order(a.ClientOrderID)
+		require.Equal(t, OrderStateExecuted, got.State)
+		require.Equal(t, 10, got.FilledCount)
+		require.Equal(t, 0, got.RemainingCount)
+
+		// The fills of an executed order are forgotten, and still
+		// dropped as duplicates.
+		m.mu.Lock()
+		require.Empty(t, m.seenFills)
+		m.mu.Unlock()
+		require.False(t, m.ApplyFill(fill))
+	})
+
+	t.Run("StaleUpdate", func(t *testing.T) {
+		d, err := m.Submit(ctx, limit("C", Yes, 10))
+		require.NoError(t, err)
+		stale, err := client.GetOrder(ctx, d.OrderID)
+		require.NoError(t, err)
+		require.True(t, m.ApplyFill(client.fill(d.OrderID, 3)))
+
+		// A snapshot taken before the fill doesn't restore the remaining
+		// count.
+		got := m.ApplyOrder(stale)
+		require.Equal(t, OrderStatePartiallyFilled, got.State)
+		require.Equal(t, 3, got.FilledCount)
+		require.Equal(t, 7, got.RemainingCount)
+
+		_, err = m.Cancel(ctx, d.ClientOrderID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Amend", func(t *testing.T) {
+		res, err := m.Amend(ctx, b.ClientOrderID, 42, 7)
+		require.NoError(t, err)
+		require.Equal(t, b, res.Old)
+		require.Equal(t, Cents(42), res.New.Price)
+		require.Equal(t, 7, res.New.Count)
+		require.Equal(t, 7, res.New.RemainingCount)
+		require.Equal(t, OrderStateResting, res.New.State)
+
+		_, err = m.Amend(ctx, "unknown", 42, 7)
+		require.ErrorIs(t, err, ErrOrderNotTracked)
+	})
+
+	t.Run("CancelAndDecrease", func(t *testing.T) {
+		got, err := m.Decrease(ctx, b.ClientOrderID, DecreaseOrderRequest{ReduceBy: 2})
+		require.NoError(t, err)
+		require.Equal(t, OrderStateResting, got.State)
+		require.Equal(t, 5, got.RemainingCount)
+
+		got, err = m.Cancel(ctx, b.ClientOrderID)
+		require.NoError(t, err)
+		require.Equal(t, OrderStateCanceled, got.State)
+
+		_, err = m.Cancel(ctx, "unknown")
+		require.ErrorIs(t, err, ErrOrderNotTracked)
+	})
+
+	t.Run("Reconcile", func(t *testing.T) {
+		// Changes made outside of the manager are picked up.
+		client.fill(c.OrderID, 1)
+		require.NoError(t, m.Reconcile(ctx))
+
+		got, _ := m.Order(c.ClientOrderID)
+		require.Equal(t, OrderStatePartiallyFilled, got.State)
+		require.Equal(t, 1, got.FilledCount)
+
+		_, err := client.CancelOrder(ctx, c.OrderID)
+		require.NoError(t, err)
+		require.NoError(t, m.Sync(ctx))
+
+		got, _ = m.Order(c.ClientOrderID)
+		require.Equal(t, OrderStateCanceled, got.State)
+		require.Empty(t, m.OpenOrders("", ""))
+	})
+}
+
+func TestOrderManagerUnknownOutcome(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newFakeClient()
+	m := NewOrderManager(client)
+
+	req := CreateOrderRequest{
+		Action:   Buy,
+		Count:    1,
+		Ticker:   "A",
+		Type:     LimitOrder,
+		Side:     Yes,
+		YesPrice: 10,
+	}
+
+	// Rejected orders aren't tracked.
+	client.rejectErr = NewHttpError(http.StatusBadRequest, "bad request")
+	_, err := m.Submit(ctx, req)
+	require.Error(t, err)
+	require.Empty(t, m.OpenOrders("", ""))
+	client.rejectErr = nil
+
+	// Orders with an unknown outcome stay pending until reconciled.
+	client.createErr = errors.New("timeout")
+	client.ordersErr = errors.New("timeout")
+	got, err := m.Submit(ctx, req)
+	var unknownErr *UnknownOrderOutcomeError
+	require.ErrorAs(t, err, &unknownErr)
+	require.Equal(t, OrderStatePending, got.State)
+	require.Empty(t, got.OrderID)
+
+	client.ordersErr = nil
+	require.NoError(t, m.Reconcile(ctx))
+	got, _ = m.Order(got.ClientOrderID)
+	require.Equal(t, OrderStateResting, got.State)
+	require.NotEmpty(t, got.OrderID)
+}
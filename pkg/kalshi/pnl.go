@@ -0,0 +1,410 @@
+package kalshi
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CostBasisMethod determines which open lots a closing trade is matched
+// against.
+type CostBasisMethod string
+
+const (
+	// FIFO closes the oldest lots first.
+	FIFO CostBasisMethod = "fifo"
+	// LIFO closes the newest lots first.
+	LIFO CostBasisMethod = "lifo"
+	// SpecificID closes the lots identified with LotTracker.Identify first,
+	// and otherwise the oldest.
+	SpecificID CostBasisMethod = "specific_id"
+	// AverageCost pools every lot of a market at their average price.
+	AverageCost CostBasisMethod = "average_cost"
+)
+
+// openLot is a quantity of contracts acquired in a single trade, or a pool
+// of them under AverageCost.
+type openLot struct {
+	Side  Side
+	Count int
+	// Cost and Fee are totals for the remaining Count.
+	Cost     Cents
+	Fee      Cents
+	Acquired time.Time
+	TradeID  string
+}
+
+// split removes n contracts from the lot, returning them with their share of
+// the cost and fee.
+func (l *openLot) split(n int) openLot {
+	part := *l
+	part.Count = n
+	part.Cost = l.Cost * Cents(n) / Cents(l.Count)
+	part.Fee = l.Fee * Cents(n) / Cents(l.Count)
+	l.Count -= n
+	l.Cost -= part.Cost
+	l.Fee -= part.Fee
+	return part
+}
+
+// closedLot is a portion of an open lot that was closed.
+type closedLot struct {
+	openLot
+	Disposed time.Time
+	// Proceeds is what the contracts were sold or settled for.
+	Proceeds Cents
+	// DisposalFee is the closing trade's fee allocated to the portion.
+	DisposalFee Cents
+}
+
+// lotBook holds the open lots of a single market. Since a Yes and a No
+// contract together always pay out 100 cents, acquiring one side closes lots
+// of the other, so all lots are on the same side.
+type lotBook struct {
+	method CostBasisMethod
+	lots   []openLot
+}
+
+// Position returns the net position in Yes contracts.
+func (b *lotBook) Position() int {
+	n := 0
+	for _, l := range b.lots {
+		if l.Side == No {
+			n -= l.Count
+		} else {
+			n += l.Count
+		}
+	}
+	return n
+}
+
+// acquire adds count contracts of side bought at price and returns the lots
+// of the opposite side closed as a result. Under SpecificID, the lots acquired
+// by the trades in picks are closed first.
+func (b *lotBook) acquire(side Side, price Cents, count int, fee Cents, t time.Time, tradeID string, picks []string) []closedLot {
+	var closed []closedLot
+	remaining := count
+	for remaining > 0 && len(b.lots) > 0 && b.lots[0].Side != side {
+		i := b.next(picks)
+		n := min(remaining, b.lots[i].Count)
+		part := b.lots[i].split(n)
+		if b.lots[i].Count == 0 {
+			b.lots = append(b.lots[:i], b.lots[i+1:]...)
+		}
+
+		closed = append(closed, closedLot{
+			openLot:  part,
+			Disposed: t,
+			// Buying the opposite side at price is selling ours at
+			// its complement.
+			Proceeds:    (100 - price) * Cents(n),
+			DisposalFee: fee * Cents(n) / Cents(count),
+		})
+		remaining -= n
+	}
+	if remaining == 0 {
+		return closed
+	}
+
+	lot := openLot{
+		Side:     side,
+		Count:    remaining,
+		Cost:     price * Cents(remaining),
+		Fee:      fee * Cents(remaining) / Cents(count),
+		Acquired: t,
+		TradeID:  tradeID,
+	}
+	if b.method == AverageCost && len(b.lots) > 0 {
+		pool := &b.lots[0]
+		pool.Count += lot.Count
+		pool.Cost += lot.Cost
+		pool.Fee += lot.Fee
+		return closed
+	}
+	b.lots = append(b.lots, lot)
+	return closed
+}
+
+// settle closes every lot at the market's result.
+func (b *lotBook) settle(result string, t time.Time) []closedLot {
+	closed := make([]closedLot, 0, len(b.lots))
+	for _, l := range b.lots {
+		var proceeds Cents
+		switch {
+		case strings.EqualFold(result, string(l.Side)):
+			proceeds = 100 * Cents(l.Count)
+		case strings.EqualFold(result, string(Yes)), strings.EqualFold(result, string(No)):
+			proceeds = 0
+		default:
+			// Voided markets are refunded.
+			proceeds = l.Cost
+		}
+		closed = append(closed, closedLot{
+			openLot:  l,
+			Disposed: t,
+			Proceeds: proceeds,
+		})
+	}
+	b.lots = nil
+	return closed
+}
+
+// next returns the index of the lot to close next.
+func (b *lotBook) next(picks []string) int {
+	switch b.method {
+	case LIFO:
+		return len(b.lots) - 1
+	case SpecificID:
+		for _, id := range picks {
+			for i, l := range b.lots {
+				if l.TradeID == id {
+					return i
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// PnLSummary totals profit and loss in cents.
+type PnLSummary struct {
+	Realized   Cents
+	Unrealized Cents
+	Fees       Cents
+}
+
+// Net returns realized and unrealized PnL net of fees.
+func (s PnLSummary) Net() Cents {
+	return s.Realized + s.Unrealized - s.Fees
+}
+
+func (s *PnLSummary) add(o PnLSummary) {
+	s.Realized += o.Realized
+	s.Unrealized += o.Unrealized
+	s.Fees += o.Fees
+}
+
+// PnLBucket is the realized PnL and fees of a time bucket.
+type PnLBucket struct {
+	Start time.Time
+	PnLSummary
+}
+
+// PnLReport is produced by PnLEngine.Report.
+type PnLReport struct {
+	Total    PnLSummary
+	ByTicker map[string]PnLSummary
+	ByEvent  map[string]PnLSummary
+	// Buckets is in chronological order and omits empty buckets.
+	Buckets []PnLBucket
+}
+
+// pnlEntry is realized PnL or fees booked at a point in time.
+type pnlEntry struct {
+	Ticker   string
+	Time     time.Time
+	Realized Cents
+	Fees     Cents
+}
+
+// PnLEngine computes profit and loss from fills and settlements.
+//
+// Positions are kept as lots using the engine's CostBasisMethod. Fees aren't
+// reported by the API per fill, so they are estimated with a FeeSchedule. To
+// track PnL per strategy, feed each strategy's fills to its own engine.
+type PnLEngine struct {
+	// EventTicker maps market tickers to event tickers. By default the last
+	// segment of the market ticker is dropped.
+	EventTicker func(marketTicker string) string
+
+	method CostBasisMethod
+	fees   FeeSchedule
+
+	mu      sync.Mutex
+	books   map[string]*lotBook
+	marks   map[string]Market
+	entries []pnlEntry
+	seen    map[string]struct{}
+}
+
+// NewPnLEngine creates a PnLEngine.
+func NewPnLEngine(method CostBasisMethod, fees FeeSchedule) *PnLEngine {
+	return &PnLEngine{
+		EventTicker: eventTickerOf,
+		method:      method,
+		fees:        fees,
+		books:       make(map[string]*lotBook),
+		marks:       make(map[string]Market),
+		seen:        make(map[string]struct{}),
+	}
+}
+
+// ApplyFill books a fill. Fills are de-duplicated by TradeID and OrderID, so
+// the same fill may be applied from multiple sources.
+func (e *PnLEngine) ApplyFill(f Fill) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := f.TradeID + "/" + f.OrderID
+	if _, ok := e.seen[key]; ok {
+		return
+	}
+	e.seen[key] = struct{}{}
+
+	side, price := acquiredSide(f)
+	fee := e.fees.Fee(f.Ticker, f.YesPrice, f.Count, f.IsTaker)
+	closed := e.book(f.Ticker).acquire(side, price, f.Count, fee, f.CreatedTime, f.TradeID, nil)
+
+	e.entries = append(e.entries, pnlEntry{
+		Ticker:   f.Ticker,
+		Time:     f.CreatedTime,
+		Realized: realizedPnL(closed),
+		Fees:     fee,
+	})
+}
+
+// ApplySettlement closes every open lot of the settled market.
+func (e *PnLEngine) ApplySettlement(s Settlement) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	closed := e.book(s.Ticker).settle(s.MarketResult, s.SettledTime)
+	e.entries = append(e.entries, pnlEntry{
+		Ticker:   s.Ticker,
+		Time:     s.SettledTime,
+		Realized: realizedPnL(closed),
+	})
+}
+
+// Mark sets the market used to value open positions in m.Ticker.
+func (e *PnLEngine) Mark(m Market) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.marks[m.Ticker] = m
+}
+
+// Position returns the net position in a market, where Yes contracts are
+// positive and No contracts negative.
+func (e *PnLEngine) Position(ticker string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if b, ok := e.books[ticker]; ok {
+		return b.Position()
+	}
+	return 0
+}
+
+// Report totals realized PnL and fees booked in [from, to) by ticker, event
+// and buckets of the given size, along with unrealized PnL of the current
+// open positions marked to the mid price. A zero from or to leaves the range
+// unbounded, and a zero bucket omits buckets.
+func (e *PnLEngine) Report(from, to time.Time, bucket time.Duration) PnLReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := PnLReport{
+		ByTicker: make(map[string]PnLSummary),
+		ByEvent:  make(map[string]PnLSummary),
+	}
+	add := func(ticker string, s PnLSummary) {
+		report.Total.add(s)
+
+		t := report.ByTicker[ticker]
+		t.add(s)
+		report.ByTicker[ticker] = t
+
+		event := e.EventTicker(ticker)
+		ev := report.ByEvent[event]
+		ev.add(s)
+		report.ByEvent[event] = ev
+	}
+
+	buckets := make(map[time.Time]*PnLBucket)
+	for _, entry := range e.entries {
+		if (!from.IsZero() && entry.Time.Before(from)) || (!to.IsZero() && !entry.Time.Before(to)) {
+			continue
+		}
+		s := PnLSummary{Realized: entry.Realized, Fees: entry.Fees}
+		add(entry.Ticker, s)
+
+		if bucket > 0 {
+			start := entry.Time.Truncate(bucket)
+			b, ok := buckets[start]
+			if !ok {
+				b = &PnLBucket{Start: start}
+				buckets[start] = b
+			}
+			b.add(s)
+		}
+	}
+	for _, b := range buckets {
+		report.Buckets = append(report.Buckets, *b)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		return report.Buckets[i].Start.Before(report.Buckets[j].Start)
+	})
+
+	for ticker, book := range e.books {
+		m, ok := e.marks[ticker]
+		if !ok || len(book.lots) == 0 {
+			continue
+		}
+		var unrealized Cents
+		for _, l := range book.lots {
+			mid := m.YesMidPrice()
+			if l.Side == No {
+				mid = m.NoMidPrice()
+			}
+			unrealized += mid*Cents(l.Count) - l.Cost
+		}
+		add(ticker, PnLSummary{Unrealized: unrealized})
+	}
+
+	return report
+}
+
+func (e *PnLEngine) book(ticker string) *lotBook {
+	b, ok := e.books[ticker]
+	if !ok {
+		b = &lotBook{method: e.method}
+		e.books[ticker] = b
+	}
+	return b
+}
+
+// acquiredSide returns the side and price, in that side's terms, of the
+// contracts a fill acquires. Selling one side is equivalent to buying the
+// other at the complementary price.
+func acquiredSide(f Fill) (Side, Cents) {
+	side := f.Side
+	if f.Action == Sell {
+		if side == Yes {
+			side = No
+		} else {
+			side = Yes
+		}
+	}
+	if side == Yes {
+		return Yes, f.YesPrice
+	}
+	return No, f.NoPrice
+}
+
+func realizedPnL(closed []closedLot) Cents {
+	var pnl Cents
+	for _, c := range closed {
+		pnl += c.Proceeds - c.Cost
+	}
+	return pnl
+}
+
+// eventTickerOf returns the event of a market ticker by dropping its last
+// segment.
+func eventTickerOf(marketTicker string) string {
+	i := strings.LastIndex(marketTicker, "-")
+	if i < 0 {
+		return marketTicker
+	}
+	return marketTicker[:i]
+}
@@ -0,0 +1,114 @@
+package kalshi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testFill(tradeID, ticker string, side Side, action OrderAction, yesPrice Cents, count int, at time.Time) Fill {
+	return Fill{
+		Action:      action,
+		Count:       count,
+		CreatedTime: at,
+		NoPrice:     100 - yesPrice,
+		OrderID:     "order-" + tradeID,
+		Side:        side,
+		Ticker:      ticker,
+		TradeID:     tradeID,
+		YesPrice:    yesPrice,
+	}
+}
+
+func TestPnLEngineCostBasis(t *testing.T) {
+	t.Parallel()
+
+	const ticker = "KXBTC-25JAN01-T100000"
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		method         CostBasisMethod
+		wantRealized   Cents
+		wantUnrealized Cents
+		wantSettled    Cents
+	}{
+		// Closes 10 @ 40 and 5 @ 60, leaving 5 @ 60.
+		{FIFO, 300 + 50, 5*75 - 300, -300},
+		// Closes 15 @ 50, leaving 5 @ 50.
+		{AverageCost, 15 * 20, 5*75 - 250, -250},
+	} {
+		e := NewPnLEngine(tt.method, FeeSchedule{})
+		e.ApplyFill(testFill("1", ticker, Yes, Buy, 40, 10, start))
+		e.ApplyFill(testFill("2", ticker, Yes, Buy, 60, 10, start.Add(time.Minute)))
+		e.ApplyFill(testFill("3", ticker, Yes, Sell, 70, 15, start.Add(2*time.Minute)))
+		e.Mark(Market{Ticker: ticker, YesBid: 74, YesAsk: 76})
+		require.Equal(t, 5, e.Position(ticker))
+
+		r := e.Report(time.Time{}, time.Time{}, 0)
+		require.Equal(t, PnLSummary{Realized: tt.wantRealized, Unrealized: tt.wantUnrealized}, r.Total, tt.method)
+
+		e.ApplySettlement(Settlement{Ticker: ticker, MarketResult: "no", SettledTime: start.Add(time.Hour)})
+		require.Equal(t, 0, e.Position(ticker))
+
+		r = e.Report(start.Add(time.Hour), time.Time{}, 0)
+		require.Equal(t, PnLSummary{Realized: tt.wantSettled}, r.Total, tt.method)
+	}
+}
+
+func TestPnLEngineOppositeSide(t *testing.T) {
+	t.Parallel()
+
+	const ticker = "KXBTC-25JAN01-T100000"
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	e := NewPnLEngine(FIFO, FeeSchedule{})
+	e.ApplyFill(testFill("1", ticker, Yes, Buy, 40, 10, start))
+	// Buying No at 30 sells Yes at 70.
+	e.ApplyFill(testFill("2", ticker, No, Buy, 70, 4, start))
+	require.Equal(t, 6, e.Position(ticker))
+	require.Equal(t, Cents(120), e.Report(time.Time{}, time.Time{}, 0).Total.Realized)
+
+	e.ApplyFill(testFill("3", ticker, No, Buy, 70, 10, start))
+	require.Equal(t, -4, e.Position(ticker))
+	require.Equal(t, Cents(120+180), e.Report(time.Time{}, time.Time{}, 0).Total.Realized)
+
+	e.Mark(Market{Ticker: ticker, YesBid: 60, YesAsk: 62, NoBid: 38, NoAsk: 40})
+	require.Equal(t, Cents(4*39-4*30), e.Report(time.Time{}, time.Time{}, 0).Total.Unrealized)
+
+	e.ApplySettlement(Settlement{Ticker: ticker, MarketResult: "no", SettledTime: start})
+	r := e.Report(time.Time{}, time.Time{}, 0)
+	require.Equal(t, Cents(120+180+4*70), r.Total.Realized)
+	require.Zero(t, r.Total.Unrealized)
+}
+
+func TestPnLEngineReport(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewPnLEngine(FIFO, DefaultFeeSchedule())
+
+	taker := testFill("1", "KXBTC-25JAN01-T100000", Yes, Buy, 50, 100, start)
+	taker.IsTaker = true
+	e.ApplyFill(taker)
+	// Fills seen twice are only booked once.
+	e.ApplyFill(taker)
+	e.ApplyFill(testFill("2", "KXBTC-25JAN01-T100000", Yes, Sell, 60, 100, start.Add(time.Hour)))
+	e.ApplyFill(testFill("3", "KXBTC-25JAN01-T90000", No, Buy, 60, 10, start.Add(2*time.Hour)))
+	e.ApplyFill(testFill("4", "KXBTC-25JAN01-T90000", No, Sell, 70, 10, start.Add(25*time.Hour)))
+
+	r := e.Report(time.Time{}, time.Time{}, 24*time.Hour)
+	require.Equal(t, PnLSummary{Realized: 1000 - 100, Fees: 175}, r.Total)
+	require.Equal(t, Cents(900-175), r.Total.Net())
+	require.Equal(t, PnLSummary{Realized: 1000, Fees: 175}, r.ByTicker["KXBTC-25JAN01-T100000"])
+	require.Equal(t, PnLSummary{Realized: -100}, r.ByTicker["KXBTC-25JAN01-T90000"])
+	require.Equal(t, map[string]PnLSummary{"KXBTC-25JAN01": r.Total}, r.ByEvent)
+	require.Equal(t, []PnLBucket{
+		{Start: start, PnLSummary: PnLSummary{Realized: 1000, Fees: 175}},
+		{Start: start.Add(24 * time.Hour), PnLSummary: PnLSummary{Realized: -100}},
+	}, r.Buckets)
+
+	r = e.Report(start, start.Add(time.Hour), 0)
+	require.Equal(t, PnLSummary{Fees: 175}, r.Total)
+	require.Empty(t, r.Buckets)
+}
@@ -121,6 +121,8 @@ func (c *Client) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Orde
 		Order Order `json:"order"`
 	}
 	if err := c.request(ctx, request{
+		Name:         "CreateOrder",
+		Ticker:       req.Ticker,
 		Method:       "POST",
 		Endpoint:     "portfolio/orders",
 		JSONRequest:  req,
@@ -135,8 +137,10 @@ func (c *Client) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Orde
 // OrdersRequest is described here:
 // https://trading-api.readme.io/reference/getorders
 type OrdersRequest struct {
-	Ticker string      `url:"ticker,omitempty"`
-	Status OrderStatus `url:"status,omitempty"`
+	CursorRequest
+	Ticker      string      `url:"ticker,omitempty"`
+	EventTicker string      `url:"event_ticker,omitempty"`
+	Status      OrderStatus `url:"status,omitempty"`
 }
 
 // Order is described here:
@@ -185,11 +189,13 @@ type OrdersResponse struct {
 	Orders []Order `json:"orders"`
 }
 
-// Orders is described here:
+// GetOrders is described here:
 // https://trading-api.readme.io/reference/getorders
 func (c *Client) GetOrders(ctx context.Context, req OrdersRequest) (*OrdersResponse, error) {
 	var resp = new(OrdersResponse)
 	if err := c.request(ctx, request{
+		Name:         "GetOrders",
+		Ticker:       req.Ticker,
 		Method:       "GET",
 		Endpoint:     "portfolio/orders",
 		QueryParams:  req,
@@ -207,6 +213,7 @@ func (c *Client) GetBalance(ctx context.Context) (Cents, error) {
 		Balance Cents `json:"balance"`
 	}
 	if err := c.request(ctx, request{
+		Name:         "GetBalance",
 		Method:       "GET",
 		Endpoint:     "portfolio/balance",
 		JSONResponse: &resp,
@@ -253,6 +260,9 @@ type FillsResponse struct {
 func (c *Client) GetFills(ctx context.Context, req FillsRequest) (*FillsResponse, error) {
 	var resp FillsResponse
 	err := c.request(ctx, request{
+		Name:         "GetFills",
+		Ticker:       req.Ticker,
+		OrderID:      req.OrderID,
 		Method:       "GET",
 		Endpoint:     "portfolio/fills",
 		QueryParams:  req,
@@ -271,9 +281,12 @@ func (c *Client) GetOrder(ctx context.Context, orderID string) (*Order, error) {
 		Order Order `json:"order"`
 	}
 	if err := c.request(ctx, request{
+		Name:         "GetOrder",
+		OrderID:      orderID,
 		Method:       "GET",
 		Endpoint:     "portfolio/orders/" + orderID,
 		JSONResponse: &resp,
+		NotFound:     ErrOrderNotFound,
 	}, authenticated); err != nil {
 		return nil, fmt.Errorf("c.request: %w", err)
 	}
@@ -287,9 +300,12 @@ func (c *Client) CancelOrder(ctx context.Context, orderID string) (*Order, error
 		Order Order `json:"order"`
 	}
 	if err := c.request(ctx, request{
+		Name:         "CancelOrder",
+		OrderID:      orderID,
 		Method:       "DELETE",
 		Endpoint:     "portfolio/orders/" + orderID,
 		JSONResponse: &resp,
+		NotFound:     ErrOrderNotFound,
 	}, authenticated); err != nil {
 		return nil, fmt.Errorf("c.request: %w", err)
 	}
@@ -310,10 +326,13 @@ func (c *Client) DecreaseOrder(ctx context.Context, orderID string, req Decrease
 		Order Order `json:"order"`
 	}
 	err := c.request(ctx, request{
+		Name:         "DecreaseOrder",
+		OrderID:      orderID,
 		Method:       "POST",
 		Endpoint:     "portfolio/orders/" + orderID + "/decrease",
 		JSONRequest:  req,
 		JSONResponse: &resp,
+		NotFound:     ErrOrderNotFound,
 	}, authenticated)
 	if err != nil {
 		return nil, err
@@ -321,6 +340,60 @@ func (c *Client) DecreaseOrder(ctx context.Context, orderID string, req Decrease
 	return &resp.Order, nil
 }
 
+// AmendOrderRequest is described here:
+// https://trading-api.readme.io/reference/amendorder.
+//
+// Ticker, Side and Action must match the order being amended. Count is the
+// new total quantity of the order.
+type AmendOrderRequest struct {
+	Ticker               string      `json:"ticker"`
+	Side                 Side        `json:"side"`
+	Action               OrderAction `json:"action"`
+	Count                int         `json:"count"`
+	YesPrice             Cents       `json:"yes_price,omitempty"`
+	NoPrice              Cents       `json:"no_price,omitempty"`
+	ClientOrderID        string      `json:"client_order_id,omitempty"`
+	UpdatedClientOrderID string      `json:"updated_client_order_id,omitempty"`
+}
+
+// SetPrice sets the price of the amended order based on its side.
+func (a *AmendOrderRequest) SetPrice(p Cents) {
+	switch a.Side {
+	case Yes:
+		a.YesPrice = p
+	case No:
+		a.NoPrice = p
+	default:
+		panic("invalid side: " + string(a.Side))
+	}
+}
+
+// AmendOrderResponse is described here:
+// https://trading-api.readme.io/reference/amendorder.
+type AmendOrderResponse struct {
+	OldOrder Order `json:"old_order"`
+	Order    Order `json:"order"`
+}
+
+// AmendOrder is described here:
+// https://trading-api.readme.io/reference/amendorder.
+func (c *Client) AmendOrder(ctx context.Context, orderID string, req AmendOrderRequest) (*AmendOrderResponse, error) {
+	var resp AmendOrderResponse
+	if err := c.request(ctx, request{
+		Name:         "AmendOrder",
+		Ticker:       req.Ticker,
+		OrderID:      orderID,
+		Method:       "POST",
+		Endpoint:     "portfolio/orders/" + orderID + "/amend",
+		JSONRequest:  req,
+		JSONResponse: &resp,
+		NotFound:     ErrOrderNotFound,
+	}, authenticated); err != nil {
+		return nil, fmt.Errorf("c.request: %w", err)
+	}
+	return &resp, nil
+}
+
 type SettlementStatus string
 
 const (
@@ -419,6 +492,8 @@ type PositionsResponse struct {
 func (c *Client) GetPositions(ctx context.Context, req PositionsRequest) (*PositionsResponse, error) {
 	var resp PositionsResponse
 	if err := c.request(ctx, request{
+		Name:         "GetPositions",
+		Ticker:       req.Ticker,
 		Method:       "GET",
 		Endpoint:     "portfolio/positions",
 		QueryParams:  req,
@@ -464,6 +539,8 @@ type SettlementsResponse struct {
 func (c *Client) GetSettlements(ctx context.Context, req SettlementsRequest) (*SettlementsResponse, error) {
 	var resp SettlementsResponse
 	if err := c.request(ctx, request{
+		Name:         "GetSettlements",
+		Ticker:       req.Ticker,
 		Method:       "GET",
 		Endpoint:     "portfolio/settlements",
 		QueryParams:  req,
@@ -2,6 +2,8 @@ package kalshi
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"testing"
 	"time"
 
@@ -155,3 +157,40 @@ func TestSettlements(t *testing.T) {
 	_, err := client.GetSettlements(ctx, SettlementsRequest{})
 	require.NoError(t, err)
 }
+
+func TestAmendOrder(t *testing.T) {
+	t.Parallel()
+
+	client := testServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/portfolio/orders/abc/amend", r.URL.Path)
+
+		var req AmendOrderRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, AmendOrderRequest{
+			Ticker:  "A",
+			Side:    No,
+			Action:  Buy,
+			Count:   3,
+			NoPrice: 45,
+		}, req)
+
+		writeJSON(t, w, http.StatusOK, map[string]any{
+			"old_order": map[string]any{"order_id": "abc", "side": "no", "no_price": 40, "remaining_count": 2},
+			"order":     map[string]any{"order_id": "abc", "side": "no", "no_price": 45, "remaining_count": 3},
+		})
+	}))
+
+	req := AmendOrderRequest{
+		Ticker: "A",
+		Side:   No,
+		Action: Buy,
+		Count:  3,
+	}
+	req.SetPrice(45)
+	resp, err := client.AmendOrder(context.Background(), "abc", req)
+	require.NoError(t, err)
+	require.Equal(t, Cents(40), resp.OldOrder.Price())
+	require.Equal(t, Cents(45), resp.Order.Price())
+	require.Equal(t, 3, resp.Order.RemainingCount)
+}
@@ -0,0 +1,554 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Risk rules. A *RiskRejectionError matches the rule it violated with
+// errors.Is.
+var (
+	ErrRiskOrderCount     = errors.New("order count limit")
+	ErrRiskOrderNotional  = errors.New("order notional limit")
+	ErrRiskTickerPosition = errors.New("ticker position limit")
+	ErrRiskEventPosition  = errors.New("event position limit")
+	ErrRiskOpenOrders     = errors.New("open orders limit")
+	ErrRiskPriceCollar    = errors.New("price collar")
+	ErrRiskDailyLoss      = errors.New("daily loss limit")
+)
+
+// RiskRejectionError is returned when an order violates a RiskLimits rule.
+type RiskRejectionError struct {
+	// Rule is one of the ErrRisk errors.
+	Rule error
+	// Limit is the bound that was crossed and Value what the order would
+	// have resulted in, in contracts, orders or cents depending on Rule.
+	Limit int
+	Value int
+	Order CreateOrderRequest
+}
+
+func (e *RiskRejectionError) Error() string {
+	return fmt.Sprintf("order rejected by %v (value %d, limit %d): %s %s", e.Rule, e.Value, e.Limit, e.Order.Ticker, e.Order.String())
+}
+
+func (e *RiskRejectionError) Unwrap() error {
+	return e.Rule
+}
+
+// RiskLimits configures a RiskClient. A zero limit disables its check.
+type RiskLimits struct {
+	// MaxOrderCount is the maximum number of contracts per order.
+	MaxOrderCount int
+	// MaxOrderNotional is the maximum cost of a single order. The cost is
+	// BuyMaxCost if set, otherwise the price times the count. Orders
+	// without a price are assumed to fill at 99 cents.
+	MaxOrderNotional Cents
+	// MaxTickerPosition is the maximum absolute position in a market the
+	// order may leave us with.
+	MaxTickerPosition int
+	// MaxEventPosition is the maximum sum of absolute positions across the
+	// markets of an event the order may leave us with.
+	MaxEventPosition int
+	// MaxOpenOrders is the maximum number of resting orders, including the
+	// new order.
+	MaxOpenOrders int
+	// PriceCollar is how far a limit price may be through the opposite side
+	// of the book. For instance, with a collar of 2 a Yes buy is rejected if
+	// it is priced more than 2 cents above the best Yes ask.
+	PriceCollar Cents
+	// DailyLossLimit is the maximum realized loss, net of fees, of the
+	// fills and settlements since the start of the day. Positions carried
+	// over from earlier days don't count towards it. Once hit, every order
+	// is rejected until the next day.
+	DailyLossLimit Cents
+	// Fees estimates the fees counted towards DailyLossLimit. Defaults to
+	// DefaultFeeSchedule.
+	Fees FeeSchedule
+	// Location determines when days start. Defaults to UTC.
+	Location *time.Location
+}
+
+// RiskClient wraps a KalshiClientLogic and rejects orders that violate its
+// RiskLimits before they are sent. All other calls pass through.
+//
+// Checks that need exchange state, such as positions or the book, fetch it
+// through the wrapped client on every order. Orders are checked and placed
+// one call at a time, so concurrent calls can't exceed a limit together, and
+// the orders of a batch count towards the limits of the orders after them.
+type RiskClient struct {
+	KalshiClientLogic
+	limits RiskLimits
+
+	// submitMu serializes checking and placing orders.
+	submitMu sync.Mutex
+
+	mu           sync.Mutex
+	eventTickers map[string]string // by market ticker
+}
+
+// riskPending is what orders that passed the checks but haven't been placed
+// yet add to the exchange state.
+type riskPending struct {
+	openOrders int
+	positions  map[string]int // position deltas by market ticker
+}
+
+func (p *riskPending) add(req CreateOrderRequest) {
+	if p.positions == nil {
+		p.positions = make(map[string]int)
+	}
+	p.openOrders++
+	p.positions[req.Ticker] += positionDelta(req)
+}
+
+// NewRiskClient creates a RiskClient enforcing limits on orders sent through
+// client.
+func NewRiskClient(client KalshiClientLogic, limits RiskLimits) *RiskClient {
+	if limits.Location == nil {
+		limits.Location = time.UTC
+	}
+	if limits.Fees.Default == (FeeRates{}) && limits.Fees.Series == nil {
+		limits.Fees = DefaultFeeSchedule()
+	}
+	return &RiskClient{
+		KalshiClientLogic: client,
+		limits:            limits,
+		eventTickers:      make(map[string]string),
+	}
+}
+
+// CreateOrder checks req and places it if it passes.
+func (r *RiskClient) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+
+	if err := r.Check(ctx, req); err != nil {
+		return nil, err
+	}
+	return r.KalshiClientLogic.CreateOrder(ctx, req)
+}
+
+// BatchCreateOrders checks each of reqs and places those that pass. Each
+// order is checked as if the orders before it that passed had been placed
+// and filled. Results are returned in the order of reqs, with rejected
+// orders carrying their rejection as Err.
+func (r *RiskClient) BatchCreateOrders(ctx context.Context, reqs []CreateOrderRequest) ([]BatchOrderResult, error) {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+
+	results := make([]BatchOrderResult, len(reqs))
+	var (
+		passed  []CreateOrderRequest
+		index   []int
+		pending riskPending
+	)
+	for i, req := range reqs {
+		if err := r.check(ctx, req, &pending); err != nil {
+			results[i] = BatchOrderResult{ClientOrderID: req.ClientOrderID, Err: err}
+			continue
+		}
+		pending.add(req)
+		passed = append(passed, req)
+		index = append(index, i)
+	}
+	if len(passed) == 0 {
+		return results, nil
+	}
+
+	placed, err := r.KalshiClientLogic.BatchCreateOrders(ctx, passed)
+	for i, result := range placed {
+		results[index[i]] = result
+	}
+	if err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// AmendOrder checks the amended order and amends it if it passes. The size
+// and price limits apply to the amended order, and the position limits to
+// the contracts it adds.
+func (r *RiskClient) AmendOrder(ctx context.Context, orderID string, req AmendOrderRequest) (*AmendOrderResponse, error) {
+	r.submitMu.Lock()
+	defer r.submitMu.Unlock()
+
+	old, err := r.KalshiClientLogic.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("r.GetOrder: %w", err)
+	}
+	order := CreateOrderRequest{
+		Action:   req.Action,
+		Count:    req.Count,
+		NoPrice:  req.NoPrice,
+		YesPrice: req.YesPrice,
+		Ticker:   req.Ticker,
+		Type:     LimitOrder,
+		Side:     req.Side,
+	}
+	if err := r.checkOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := r.checkDailyLoss(ctx, order); err != nil {
+		return nil, err
+	}
+	// The order is already resting, so it isn't counted twice.
+	if err := r.checkOpenOrders(ctx, order, &riskPending{openOrders: -1}); err != nil {
+		return nil, err
+	}
+	added := order
+	added.Count = req.Count - old.TakerFillCount - old.MakerFillCount - old.RemainingCount
+	if added.Count > 0 {
+		if err := r.checkPositions(ctx, added, &riskPending{}); err != nil {
+			return nil, err
+		}
+	}
+	return r.KalshiClientLogic.AmendOrder(ctx, orderID, req)
+}
+
+// Check returns a *RiskRejectionError if req violates any limit.
+func (r *RiskClient) Check(ctx context.Context, req CreateOrderRequest) error {
+	return r.check(ctx, req, &riskPending{})
+}
+
+func (r *RiskClient) check(ctx context.Context, req CreateOrderRequest, pending *riskPending) error {
+	if err := r.checkOrder(ctx, req); err != nil {
+		return err
+	}
+	if err := r.checkDailyLoss(ctx, req); err != nil {
+		return err
+	}
+	if err := r.checkOpenOrders(ctx, req, pending); err != nil {
+		return err
+	}
+	return r.checkPositions(ctx, req, pending)
+}
+
+// checkOrder checks the limits that only depend on the order itself and the
+// book.
+func (r *RiskClient) checkOrder(ctx context.Context, req CreateOrderRequest) error {
+	l := r.limits
+	if l.MaxOrderCount > 0 && req.Count > l.MaxOrderCount {
+		return r.reject(ErrRiskOrderCount, l.MaxOrderCount, req.Count, req)
+	}
+	if l.MaxOrderNotional > 0 {
+		if notional := orderNotional(req); notional > l.MaxOrderNotional {
+			return r.reject(ErrRiskOrderNotional, int(l.MaxOrderNotional), int(notional), req)
+		}
+	}
+	if l.PriceCollar > 0 && requestPrice(req) > 0 {
+		return r.checkCollar(ctx, req)
+	}
+	return nil
+}
+
+func (r *RiskClient) checkCollar(ctx context.Context, req CreateOrderRequest) error {
+	book, err := r.KalshiClientLogic.MarketOrderBook(ctx, req.Ticker)
+	if err != nil {
+		return fmt.Errorf("r.MarketOrderBook: %w", err)
+	}
+
+	price := requestPrice(req)
+	if req.Action == Buy {
+		ask, ok := book.BestYesAsk()
+		if req.Side == No {
+			ask, ok = book.BestNoAsk()
+		}
+		if ok && price > ask+r.limits.PriceCollar {
+			return r.reject(ErrRiskPriceCollar, int(ask+r.limits.PriceCollar), int(price), req)
+		}
+		return nil
+	}
+
+	bid, ok := book.BestYesBid()
+	if req.Side == No {
+		bid, ok = book.BestNoBid()
+	}
+	if ok && price < bid-r.limits.PriceCollar {
+		return r.reject(ErrRiskPriceCollar, int(bid-r.limits.PriceCollar), int(price), req)
+	}
+	return nil
+}
+
+func (r *RiskClient) checkOpenOrders(ctx context.Context, req CreateOrderRequest, pending *riskPending) error {
+	if r.limits.MaxOpenOrders <= 0 {
+		return nil
+	}
+
+	open := pending.openOrders
+	ordersReq := OrdersRequest{Status: Resting}
+	for {
+		resp, err := r.KalshiClientLogic.GetOrders(ctx, ordersReq)
+		if err != nil {
+			return fmt.Errorf("r.GetOrders: %w", err)
+		}
+		open += len(resp.Orders)
+		if resp.Cursor == "" {
+			break
+		}
+		ordersReq.Cursor = resp.Cursor
+	}
+	if open+1 > r.limits.MaxOpenOrders {
+		return r.reject(ErrRiskOpenOrders, r.limits.MaxOpenOrders, open+1, req)
+	}
+	return nil
+}
+
+func (r *RiskClient) checkPositions(ctx context.Context, req CreateOrderRequest, pending *riskPending) error {
+	l := r.limits
+	if l.MaxTickerPosition <= 0 && l.MaxEventPosition <= 0 {
+		return nil
+	}
+
+	var (
+		posReq PositionsRequest
+		err    error
+	)
+	if l.MaxEventPosition > 0 {
+		posReq.EventTicker, err = r.eventTicker(ctx, req.Ticker)
+		if err != nil {
+			return err
+		}
+	} else {
+		posReq.Ticker = req.Ticker
+	}
+	positions, err := marketPositions(ctx, r.KalshiClientLogic, posReq)
+	if err != nil {
+		return err
+	}
+
+	byTicker := make(map[string]int, len(positions))
+	for _, p := range positions {
+		byTicker[p.Ticker] += p.Position
+	}
+	for ticker, delta := range pending.positions {
+		if ticker != req.Ticker && posReq.EventTicker != "" {
+			// The pending order's event is cached by its own check.
+			if event, _ := r.eventTicker(ctx, ticker); event != posReq.EventTicker {
+				continue
+			}
+		}
+		byTicker[ticker] += delta
+	}
+
+	var current, eventTotal int
+	for ticker, position := range byTicker {
+		if ticker == req.Ticker {
+			current = position
+			continue
+		}
+		eventTotal += abs(position)
+	}
+	next := current + positionDelta(req)
+	if abs(next) <= abs(current) {
+		// Orders that reduce our position are always allowed.
+		return nil
+	}
+
+	if l.MaxTickerPosition > 0 && abs(next) > l.MaxTickerPosition {
+		return r.reject(ErrRiskTickerPosition, l.MaxTickerPosition, abs(next), req)
+	}
+	if l.MaxEventPosition > 0 && eventTotal+abs(next) > l.MaxEventPosition {
+		return r.reject(ErrRiskEventPosition, l.MaxEventPosition, eventTotal+abs(next), req)
+	}
+	return nil
+}
+
+func (r *RiskClient) checkDailyLoss(ctx context.Context, req CreateOrderRequest) error {
+	if r.limits.DailyLossLimit <= 0 {
+		return nil
+	}
+
+	now := time.Now().In(r.limits.Location)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, r.limits.Location)
+
+	fills, err := fillsSince(ctx, r.KalshiClientLogic, day)
+	if err != nil {
+		return err
+	}
+	settlements, err := settlementsSince(ctx, r.KalshiClientLogic, day)
+	if err != nil {
+		return err
+	}
+
+	carried, err := r.carriedPositions(ctx, fills, settlements)
+	if err != nil {
+		return err
+	}
+
+	// Every market settles after its last fill, so the fills are booked
+	// first. Fills closing contracts carried over only count their fees,
+	// so the lots booked are those opened today.
+	pnl := NewPnLEngine(FIFO, r.limits.Fees)
+	var carriedFees Cents
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].CreatedTime.Before(fills[j].CreatedTime) })
+	for _, f := range fills {
+		delta := fillDelta(f)
+		if c := carried[f.Ticker]; c != 0 && (c > 0) != (delta > 0) {
+			n := min(f.Count, abs(c))
+			if c > 0 {
+				carried[f.Ticker] -= n
+			} else {
+				carried[f.Ticker] += n
+			}
+			carriedFees += r.limits.Fees.Fee(f.Ticker, f.YesPrice, n, f.IsTaker)
+			f.Count -= n
+		}
+		if f.Count > 0 {
+			pnl.ApplyFill(f)
+		}
+	}
+	for _, s := range settlements {
+		pnl.ApplySettlement(s)
+	}
+	total := pnl.Report(time.Time{}, time.Time{}, 0).Total
+	loss := total.Fees + carriedFees - total.Realized
+
+	if loss > r.limits.DailyLossLimit {
+		return r.reject(ErrRiskDailyLoss, int(r.limits.DailyLossLimit), int(loss), req)
+	}
+	return nil
+}
+
+// carriedPositions returns the positions held at the start of the day, by
+// market: the current positions less the fills since. Markets settled since
+// held the position they settled.
+func (r *RiskClient) carriedPositions(ctx context.Context, fills []Fill, settlements []Settlement) (map[string]int, error) {
+	positions, err := marketPositions(ctx, r.KalshiClientLogic, PositionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	carried := make(map[string]int)
+	for _, p := range positions {
+		carried[p.Ticker] += p.Position
+	}
+	for _, s := range settlements {
+		carried[s.Ticker] = s.YesCount - s.NoCount
+	}
+	for _, f := range fills {
+		carried[f.Ticker] -= fillDelta(f)
+	}
+	return carried, nil
+}
+
+func (r *RiskClient) eventTicker(ctx context.Context, ticker string) (string, error) {
+	r.mu.Lock()
+	event, ok := r.eventTickers[ticker]
+	r.mu.Unlock()
+	if ok {
+		return event, nil
+	}
+
+	market, err := r.KalshiClientLogic.Market(ctx, ticker)
+	if err != nil {
+		return "", fmt.Errorf("r.Market: %w", err)
+	}
+
+	r.mu.Lock()
+	r.eventTickers[ticker] = market.EventTicker
+	r.mu.Unlock()
+	return market.EventTicker, nil
+}
+
+func (r *RiskClient) reject(rule error, limit, value int, req CreateOrderRequest) error {
+	return &RiskRejectionError{
+		Rule:  rule,
+		Limit: limit,
+		Value: value,
+		Order: req,
+	}
+}
+
+// marketPositions returns every market position matching req.
+func marketPositions(ctx context.Context, client KalshiClientLogic, req PositionsRequest) ([]MarketPosition, error) {
+	var positions []MarketPosition
+	for {
+		resp, err := client.GetPositions(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("client.GetPositions: %w", err)
+		}
+		positions = append(positions, resp.MarketPositions...)
+		if resp.Cursor == "" {
+			return positions, nil
+		}
+		req.Cursor = resp.Cursor
+	}
+}
+
+// fillsSince returns every fill since t.
+func fillsSince(ctx context.Context, client KalshiClientLogic, t time.Time) ([]Fill, error) {
+	req := FillsRequest{MinTS: Timestamp(t)}
+	var fills []Fill
+	for {
+		resp, err := client.GetFills(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("client.GetFills: %w", err)
+		}
+		fills = append(fills, resp.Fills...)
+		if resp.Cursor == "" {
+			return fills, nil
+		}
+		req.Cursor = resp.Cursor
+	}
+}
+
+// settlementsSince returns every settlement since t.
+func settlementsSince(ctx context.Context, client KalshiClientLogic, t time.Time) ([]Settlement, error) {
+	req := SettlementsRequest{MinTs: t.Unix()}
+	var settlements []Settlement
+	for {
+		resp, err := client.GetSettlements(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("client.GetSettlements: %w", err)
+		}
+		settlements = append(settlements, resp.Settlements...)
+		if resp.Cursor == "" {
+			return settlements, nil
+		}
+		req.Cursor = resp.Cursor
+	}
+}
+
+// orderNotional returns the most an order may cost.
+func orderNotional(req CreateOrderRequest) Cents {
+	if req.BuyMaxCost > 0 {
+		return req.BuyMaxCost
+	}
+	price := requestPrice(req)
+	if price <= 0 {
+		price = 99
+	}
+	return price * Cents(req.Count)
+}
+
+// positionDelta returns how an order changes our position, where Yes
+// contracts are positive and No contracts negative.
+func positionDelta(req CreateOrderRequest) int {
+	delta := req.Count
+	if req.Side == No {
+		delta = -delta
+	}
+	if req.Action == Sell {
+		delta = -delta
+	}
+	return delta
+}
+
+// fillDelta returns how a fill changed our position, as positionDelta.
+func fillDelta(f Fill) int {
+	if side, _ := acquiredSide(f); side == No {
+		return -f.Count
+	}
+	return f.Count
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
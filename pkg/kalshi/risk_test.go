@@ -0,0 +1,277 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	buy := func(ticker string, side Side, count int, price Cents) CreateOrderRequest {
+		req := CreateOrderRequest{
+			Action: Buy,
+			Count:  count,
+			Ticker: ticker,
+			Type:   LimitOrder,
+			Side:   side,
+		}
+		req.SetPrice(price)
+		return req
+	}
+	requireRejected := func(t *testing.T, err error, rule error) {
+		t.Helper()
+		var rejection *RiskRejectionError
+		require.ErrorAs(t, err, &rejection)
+		require.ErrorIs(t, err, rule)
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		r := NewRiskClient(client, RiskLimits{
+			MaxOrderCount:    10,
+			MaxOrderNotional: 500,
+		})
+
+		_, err := r.CreateOrder(ctx, buy("A", Yes, 11, 1))
+		requireRejected(t, err, ErrRiskOrderCount)
+
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 10, 51))
+		requireRejected(t, err, ErrRiskOrderNotional)
+
+		// Market orders are assumed to fill at the worst price.
+		_, err = r.CreateOrder(ctx, CreateOrderRequest{Action: Buy, Count: 6, Ticker: "A", Type: MarketOrder, Side: Yes})
+		requireRejected(t, err, ErrRiskOrderNotional)
+
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 10, 50))
+		require.NoError(t, err)
+		require.Equal(t, 1, client.creates)
+	})
+
+	t.Run("Collar", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.books["A"] = &OrderBook{
+			YesBids: OrderBookBids{{38, 10}, {40, 10}},
+			NoBids:  OrderBookBids{{50, 10}, {55, 10}},
+		}
+		r := NewRiskClient(client, RiskLimits{PriceCollar: 2})
+
+		// Best Yes ask is 45.
+		_, err := r.CreateOrder(ctx, buy("A", Yes, 1, 48))
+		requireRejected(t, err, ErrRiskPriceCollar)
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 1, 47))
+		require.NoError(t, err)
+
+		// Best No ask is 60.
+		_, err = r.CreateOrder(ctx, buy("A", No, 1, 63))
+		requireRejected(t, err, ErrRiskPriceCollar)
+
+		// Best Yes bid is 40.
+		sell := buy("A", Yes, 1, 37)
+		sell.Action = Sell
+		_, err = r.CreateOrder(ctx, sell)
+		requireRejected(t, err, ErrRiskPriceCollar)
+		sell.SetPrice(38)
+		_, err = r.CreateOrder(ctx, sell)
+		require.NoError(t, err)
+
+		// No book, no collar.
+		_, err = r.CreateOrder(ctx, buy("B", Yes, 1, 99))
+		require.NoError(t, err)
+	})
+
+	t.Run("Positions", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.markets["A"] = &Market{Ticker: "A", EventTicker: "E"}
+		client.markets["B"] = &Market{Ticker: "B", EventTicker: "E"}
+		client.positions = []MarketPosition{
+			{Ticker: "A", Position: 8},
+			{Ticker: "B", Position: -5},
+		}
+		r := NewRiskClient(client, RiskLimits{
+			MaxTickerPosition: 10,
+			MaxEventPosition:  15,
+		})
+
+		_, err := r.CreateOrder(ctx, buy("A", Yes, 3, 10))
+		requireRejected(t, err, ErrRiskTickerPosition)
+
+		// Reducing is fine even if the result is over the limit.
+		_, err = r.CreateOrder(ctx, buy("A", No, 3, 10))
+		require.NoError(t, err)
+
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 2, 10))
+		require.NoError(t, err)
+
+		_, err = r.CreateOrder(ctx, buy("B", No, 3, 10))
+		requireRejected(t, err, ErrRiskEventPosition)
+	})
+
+	t.Run("OpenOrders", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		r := NewRiskClient(client, RiskLimits{MaxOpenOrders: 2})
+
+		_, err := r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		require.NoError(t, err)
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		require.NoError(t, err)
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		requireRejected(t, err, ErrRiskOpenOrders)
+	})
+
+	t.Run("DailyLoss", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		// Yesterday's settlements don't count.
+		client.settlements = []Settlement{
+			{MarketResult: "no", SettledTime: time.Now().Add(-48 * time.Hour), Ticker: "B", YesCount: 100, YesTotalCost: 5000},
+		}
+		r := NewRiskClient(client, RiskLimits{DailyLossLimit: 50})
+
+		order, err := r.CreateOrder(ctx, buy("A", Yes, 10, 60))
+		require.NoError(t, err)
+		client.fill(order.OrderID, 10)
+		client.mu.Lock()
+		client.positions = []MarketPosition{{Ticker: "A", Position: 10}}
+		client.mu.Unlock()
+		resting, err := r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		require.NoError(t, err)
+
+		// The market settles against us, realizing a loss of 600.
+		client.mu.Lock()
+		client.positions = nil
+		client.settlements = append(client.settlements, Settlement{MarketResult: "no", SettledTime: time.Now(), Ticker: "A", YesCount: 10, YesTotalCost: 600})
+		client.mu.Unlock()
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		requireRejected(t, err, ErrRiskDailyLoss)
+		_, err = r.AmendOrder(ctx, resting.OrderID, AmendOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 2, YesPrice: 10})
+		requireRejected(t, err, ErrRiskDailyLoss)
+	})
+
+	t.Run("DailyLossCarriedOver", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		r := NewRiskClient(client, RiskLimits{DailyLossLimit: 50})
+
+		// Selling 10 Yes held since yesterday, of a market that then
+		// settles, opens no position of today.
+		sell := buy("B", Yes, 10, 50)
+		sell.Action, sell.NoPrice = Sell, 50
+		order, err := r.CreateOrder(ctx, sell)
+		require.NoError(t, err)
+		client.fill(order.OrderID, 10)
+		client.mu.Lock()
+		client.settlements = []Settlement{{MarketResult: "yes", SettledTime: time.Now(), Ticker: "B"}}
+		client.mu.Unlock()
+
+		_, err = r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		require.NoError(t, err)
+	})
+
+	t.Run("Amend", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.positions = []MarketPosition{{Ticker: "A", Position: 8}}
+		r := NewRiskClient(client, RiskLimits{MaxTickerPosition: 10, MaxOpenOrders: 1})
+
+		order, err := r.CreateOrder(ctx, buy("A", Yes, 1, 10))
+		require.NoError(t, err)
+
+		// Only the contracts added count towards the position, and the
+		// order itself towards the open orders once.
+		amend := AmendOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 4, YesPrice: 10}
+		_, err = r.AmendOrder(ctx, order.OrderID, amend)
+		requireRejected(t, err, ErrRiskTickerPosition)
+		amend.Count = 3
+		_, err = r.AmendOrder(ctx, order.OrderID, amend)
+		require.NoError(t, err)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		t.Parallel()
+		client := &batchFakeClient{fakeClient: newFakeClient()}
+		r := NewRiskClient(client, RiskLimits{
+			MaxTickerPosition: 10,
+			MaxOpenOrders:     3,
+		})
+
+		// Each order is within the limits on its own, but not together
+		// with the orders before it.
+		results, err := r.BatchCreateOrders(ctx, []CreateOrderRequest{
+			buy("A", Yes, 6, 10),
+			buy("A", Yes, 6, 10),
+			buy("A", No, 2, 10),
+			buy("B", Yes, 1, 10),
+			buy("B", Yes, 1, 10),
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		require.NoError(t, results[0].Err)
+		require.ErrorIs(t, results[1].Err, ErrRiskTickerPosition)
+		require.NoError(t, results[2].Err)
+		require.NoError(t, results[3].Err)
+		require.ErrorIs(t, results[4].Err, ErrRiskOpenOrders)
+		require.Equal(t, 3, client.creates)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		r := NewRiskClient(client, RiskLimits{MaxOpenOrders: 3})
+
+		var (
+			wg     sync.WaitGroup
+			placed atomic.Int32
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := r.CreateOrder(ctx, buy("A", Yes, 1, 10)); err == nil {
+					placed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.EqualValues(t, 3, placed.Load())
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		t.Parallel()
+		client := &batchFakeClient{fakeClient: newFakeClient()}
+		r := NewRiskClient(client, RiskLimits{MaxOrderCount: 5})
+
+		results, err := r.BatchCreateOrders(ctx, []CreateOrderRequest{
+			buy("A", Yes, 1, 10),
+			buy("A", Yes, 6, 10),
+			buy("A", Yes, 2, 10),
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.NoError(t, results[0].Err)
+		require.True(t, errors.Is(results[1].Err, ErrRiskOrderCount))
+		require.NoError(t, results[2].Err)
+		require.Equal(t, 2, client.creates)
+	})
+}
+
+// batchFakeClient implements BatchCreateOrders by fanning out to a fakeClient.
+type batchFakeClient struct {
+	*fakeClient
+}
+
+func (c *batchFakeClient) BatchCreateOrders(ctx context.Context, reqs []CreateOrderRequest) ([]BatchOrderResult, error) {
+	return FanOutCreateOrders(ctx, c.fakeClient, reqs, 1), nil
+}
@@ -0,0 +1,107 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotMutuallyExclusive is returned by AnalyzeScenarios for events whose
+// markets may resolve Yes together.
+var ErrNotMutuallyExclusive = errors.New("event is not mutually exclusive")
+
+// Scenario is an outcome of a mutually exclusive event in which exactly one
+// market resolves Yes.
+type Scenario struct {
+	// Ticker is the market resolving Yes.
+	Ticker string
+	// Probability is the market-implied probability of the scenario.
+	Probability float64
+	// Payoff is what the positions settle for in the scenario, less their
+	// cost.
+	Payoff Cents
+}
+
+// ScenarioAnalysis is the payoff of our positions in a mutually exclusive
+// event across its scenarios.
+type ScenarioAnalysis struct {
+	EventTicker string
+	// Scenarios has one entry per market of the event.
+	Scenarios []Scenario
+	// MaxLoss is the most lost in any scenario, or zero if no scenario
+	// loses money.
+	MaxLoss Cents
+	// MaxGain is the most gained in any scenario, or zero if no scenario
+	// makes money.
+	MaxGain Cents
+	// ExpectedValue is the probability-weighted payoff in cents.
+	ExpectedValue float64
+}
+
+// AnalyzeScenarios computes the payoff of positions in each scenario of a
+// mutually exclusive event, where exactly one of markets resolves Yes.
+//
+// Scenario probabilities are the Yes mid prices normalized to sum to one.
+// Positions in markets outside the event are ignored; to size a new bet,
+// include it as a hypothetical position.
+func AnalyzeScenarios(event Event, markets []Market, positions []MarketPosition) (*ScenarioAnalysis, error) {
+	if !event.MutuallyExclusive {
+		return nil, fmt.Errorf("%s: %w", event.EventTicker, ErrNotMutuallyExclusive)
+	}
+	if len(markets) == 0 {
+		return nil, fmt.Errorf("%s: no markets", event.EventTicker)
+	}
+
+	inEvent := make(map[string]bool, len(markets))
+	var totalMid Cents
+	for i := range markets {
+		inEvent[markets[i].Ticker] = true
+		totalMid += markets[i].YesMidPrice()
+	}
+
+	analysis := &ScenarioAnalysis{
+		EventTicker: event.EventTicker,
+		Scenarios:   make([]Scenario, 0, len(markets)),
+	}
+	for i := range markets {
+		scenario := Scenario{
+			Ticker:      markets[i].Ticker,
+			Probability: 1 / float64(len(markets)),
+		}
+		if totalMid > 0 {
+			scenario.Probability = float64(markets[i].YesMidPrice()) / float64(totalMid)
+		}
+
+		for _, p := range positions {
+			if !inEvent[p.Ticker] {
+				continue
+			}
+			won := p.Position > 0 && p.Ticker == scenario.Ticker ||
+				p.Position < 0 && p.Ticker != scenario.Ticker
+			if won {
+				scenario.Payoff += 100 * Cents(p.AbsPosition())
+			}
+			scenario.Payoff -= p.MarketExposure
+		}
+
+		analysis.Scenarios = append(analysis.Scenarios, scenario)
+		analysis.MaxLoss = max(analysis.MaxLoss, -scenario.Payoff)
+		analysis.MaxGain = max(analysis.MaxGain, scenario.Payoff)
+		analysis.ExpectedValue += scenario.Probability * float64(scenario.Payoff)
+	}
+	return analysis, nil
+}
+
+// AnalyzeEventScenarios fetches an event, its markets and our positions in
+// them, and calls AnalyzeScenarios.
+func AnalyzeEventScenarios(ctx context.Context, client KalshiClientLogic, eventTicker string) (*ScenarioAnalysis, error) {
+	event, err := client.Event(ctx, eventTicker)
+	if err != nil {
+		return nil, fmt.Errorf("client.Event: %w", err)
+	}
+	positions, err := marketPositions(ctx, client, PositionsRequest{EventTicker: eventTicker})
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeScenarios(event.Event, event.Markets, positions)
+}
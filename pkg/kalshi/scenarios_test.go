@@ -0,0 +1,91 @@
+package kalshi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeScenarios(t *testing.T) {
+	t.Parallel()
+
+	event := Event{EventTicker: "KXFED-25JAN", MutuallyExclusive: true}
+	markets := []Market{
+		{Ticker: "KXFED-25JAN-CUT", YesBid: 19, YesAsk: 21},
+		{Ticker: "KXFED-25JAN-HOLD", YesBid: 69, YesAsk: 71},
+		{Ticker: "KXFED-25JAN-HIKE", YesBid: 9, YesAsk: 11},
+	}
+	positions := []MarketPosition{
+		// 10 Yes at 20.
+		{Ticker: "KXFED-25JAN-CUT", Position: 10, MarketExposure: 200},
+		// 5 No at 25.
+		{Ticker: "KXFED-25JAN-HOLD", Position: -5, MarketExposure: 125},
+		// Other events are ignored.
+		{Ticker: "KXCPI-25JAN-T3", Position: 100, MarketExposure: 5000},
+	}
+
+	a, err := AnalyzeScenarios(event, markets, positions)
+	require.NoError(t, err)
+	require.Equal(t, "KXFED-25JAN", a.EventTicker)
+	require.Len(t, a.Scenarios, 3)
+
+	require.Equal(t, "KXFED-25JAN-CUT", a.Scenarios[0].Ticker)
+	require.Equal(t, Cents(1000+500-325), a.Scenarios[0].Payoff)
+	require.Equal(t, Cents(-325), a.Scenarios[1].Payoff)
+	require.Equal(t, Cents(500-325), a.Scenarios[2].Payoff)
+
+	require.InDelta(t, 0.2, a.Scenarios[0].Probability, 1e-9)
+	require.InDelta(t, 0.7, a.Scenarios[1].Probability, 1e-9)
+	require.Equal(t, Cents(325), a.MaxLoss)
+	require.Equal(t, Cents(1175), a.MaxGain)
+	require.InDelta(t, 0.2*1175-0.7*325+0.1*175, a.ExpectedValue, 1e-9)
+
+	_, err = AnalyzeScenarios(Event{EventTicker: "KXCPI-25JAN"}, markets, positions)
+	require.ErrorIs(t, err, ErrNotMutuallyExclusive)
+}
+
+type eventClient struct {
+	KalshiClientLogic
+	event     EventResponse
+	positions []MarketPosition
+}
+
+func (c *eventClient) Event(context.Context, string) (*EventResponse, error) {
+	return &c.event, nil
+}
+
+func (c *eventClient) GetPositions(_ context.Context, req PositionsRequest) (*PositionsResponse, error) {
+	var resp PositionsResponse
+	for _, p := range c.positions {
+		if eventTickerOf(p.Ticker) == req.EventTicker {
+			resp.MarketPositions = append(resp.MarketPositions, p)
+		}
+	}
+	return &resp, nil
+}
+
+func TestAnalyzeEventScenarios(t *testing.T) {
+	t.Parallel()
+
+	client := &eventClient{
+		event: EventResponse{
+			Event: Event{EventTicker: "KXFED-25JAN", MutuallyExclusive: true},
+			Markets: []Market{
+				{Ticker: "KXFED-25JAN-CUT"},
+				{Ticker: "KXFED-25JAN-HOLD"},
+			},
+		},
+		positions: []MarketPosition{
+			{Ticker: "KXFED-25JAN-HOLD", Position: 10, MarketExposure: 500},
+		},
+	}
+	a, err := AnalyzeEventScenarios(context.Background(), client, "KXFED-25JAN")
+	require.NoError(t, err)
+	require.Equal(t, []Scenario{
+		// Without quotes, scenarios are equally likely.
+		{Ticker: "KXFED-25JAN-CUT", Probability: 0.5, Payoff: -500},
+		{Ticker: "KXFED-25JAN-HOLD", Probability: 0.5, Payoff: 500},
+	}, a.Scenarios)
+	require.Zero(t, a.ExpectedValue)
+}
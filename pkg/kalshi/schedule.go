@@ -0,0 +1,243 @@
+package kalshi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	// The exchange's timezone is embedded so that schedules parse on hosts
+	// without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+const (
+	// ExchangeTimezone is the timezone of the exchange's standard hours.
+	ExchangeTimezone = "America/New_York"
+	// scheduleHorizon is how far ahead ExchangeSchedule looks for the next
+	// open or close.
+	scheduleHorizon = 14 * 24 * time.Hour
+)
+
+// TimeOfDay is a time of day as the duration since midnight.
+type TimeOfDay time.Duration
+
+// ParseTimeOfDay parses times of day such as "08:00" or "08:00:30".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return TimeOfDay(t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day %q", s)
+}
+
+// On returns the time of day on the date of day in loc.
+func (d TimeOfDay) On(day time.Time, loc *time.Location) time.Time {
+	day = day.In(loc)
+	// Setting the wall clock rather than adding d to midnight keeps the time
+	// right on days with a DST change.
+	clock := time.Duration(d)
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), int(clock%time.Minute/time.Second), 0, loc)
+}
+
+func (d TimeOfDay) String() string {
+	clock := time.Duration(d)
+	return fmt.Sprintf("%02d:%02d", int(clock/time.Hour), int(clock%time.Hour/time.Minute))
+}
+
+// TradingHours are the hours the exchange is open on a day. A Close at or
+// before Open is on the following day.
+type TradingHours struct {
+	Open  TimeOfDay
+	Close TimeOfDay
+}
+
+// MaintenanceWindow is a period during which the exchange is closed.
+type MaintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t is within the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// ExchangeSchedule is a parsed ExchangeScheduleResponse.
+type ExchangeSchedule struct {
+	// Location is the exchange's timezone.
+	Location *time.Location
+	// Hours holds the trading hours of the days the exchange opens.
+	Hours       map[time.Weekday]TradingHours
+	Maintenance []MaintenanceWindow
+}
+
+// Parse parses the schedule in the exchange's timezone.
+func (r *ExchangeScheduleResponse) Parse() (*ExchangeSchedule, error) {
+	loc, err := time.LoadLocation(ExchangeTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("time.LoadLocation: %w", err)
+	}
+
+	s := &ExchangeSchedule{
+		Location: loc,
+		Hours:    make(map[time.Weekday]TradingHours),
+	}
+	standard := r.Schedule.StandardHours
+	for day, hours := range map[time.Weekday]RawTradingHours{
+		time.Monday:    standard.Monday,
+		time.Tuesday:   standard.Tuesday,
+		time.Wednesday: standard.Wednesday,
+		time.Thursday:  standard.Thursday,
+		time.Friday:    standard.Friday,
+		time.Saturday:  standard.Saturday,
+		time.Sunday:    standard.Sunday,
+	} {
+		if hours.OpenTime == "" || hours.CloseTime == "" {
+			continue
+		}
+		open, err := ParseTimeOfDay(hours.OpenTime)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", day, err)
+		}
+		closeTime, err := ParseTimeOfDay(hours.CloseTime)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", day, err)
+		}
+		s.Hours[day] = TradingHours{Open: open, Close: closeTime}
+	}
+
+	for _, w := range r.Schedule.MaintenanceWindows {
+		start, err := time.Parse(time.RFC3339, w.StartDatetime)
+		if err != nil {
+			return nil, fmt.Errorf("time.Parse: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, w.EndDatetime)
+		if err != nil {
+			return nil, fmt.Errorf("time.Parse: %w", err)
+		}
+		s.Maintenance = append(s.Maintenance, MaintenanceWindow{Start: start, End: end})
+	}
+	sort.Slice(s.Maintenance, func(i, j int) bool {
+		return s.Maintenance[i].Start.Before(s.Maintenance[j].Start)
+	})
+	return s, nil
+}
+
+// IsOpen reports whether the exchange is open at t.
+func (s *ExchangeSchedule) IsOpen(t time.Time) bool {
+	for _, ss := range s.sessions(t) {
+		if ss.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// InMaintenance reports whether t is within a maintenance window.
+func (s *ExchangeSchedule) InMaintenance(t time.Time) bool {
+	for _, w := range s.Maintenance {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the earliest time at or after t that the exchange is open,
+// or the zero time if it doesn't open within two weeks.
+func (s *ExchangeSchedule) NextOpen(t time.Time) time.Time {
+	for _, ss := range s.sessions(t) {
+		if ss.contains(t) {
+			return t
+		}
+		if ss.Start.After(t) {
+			return ss.Start
+		}
+	}
+	return time.Time{}
+}
+
+// NextClose returns the earliest time at or after t that the exchange is
+// closed, including for maintenance, or the zero time if it doesn't close
+// within two weeks.
+func (s *ExchangeSchedule) NextClose(t time.Time) time.Time {
+	horizon := t.Add(scheduleHorizon)
+	for _, ss := range s.sessions(t) {
+		if ss.contains(t) {
+			if !ss.End.Before(horizon) {
+				return time.Time{}
+			}
+			return ss.End
+		}
+	}
+	return t
+}
+
+// sessions returns the periods the exchange is open from the day before t
+// until scheduleHorizon after t, in chronological order. Sessions that
+// adjoin are merged, and maintenance windows are cut out.
+func (s *ExchangeSchedule) sessions(t time.Time) []session {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	horizon := t.Add(scheduleHorizon)
+
+	var sessions []session
+	for day := t.AddDate(0, 0, -1); day.Before(horizon.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		hours, ok := s.Hours[day.Weekday()]
+		if !ok {
+			continue
+		}
+		start := hours.Open.On(day, loc)
+		if !start.Before(horizon) {
+			break
+		}
+		end := hours.Close.On(day, loc)
+		if !end.After(start) {
+			end = hours.Close.On(day.AddDate(0, 0, 1), loc)
+		}
+		if end.After(horizon) {
+			end = horizon
+		}
+		if n := len(sessions); n > 0 && !start.After(sessions[n-1].End) {
+			if end.After(sessions[n-1].End) {
+				sessions[n-1].End = end
+			}
+			continue
+		}
+		sessions = append(sessions, session{Start: start, End: end})
+	}
+
+	for _, w := range s.Maintenance {
+		var cut []session
+		for _, ss := range sessions {
+			if !w.Start.Before(ss.End) || !w.End.After(ss.Start) {
+				cut = append(cut, ss)
+				continue
+			}
+			if w.Start.After(ss.Start) {
+				cut = append(cut, session{Start: ss.Start, End: w.Start})
+			}
+			if w.End.Before(ss.End) {
+				cut = append(cut, session{Start: w.End, End: ss.End})
+			}
+		}
+		sessions = cut
+	}
+	return sessions
+}
+
+// session is a period during which the exchange is open.
+type session struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (s session) contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
@@ -0,0 +1,107 @@
+package kalshi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testScheduleJSON = `{
+	"schedule": {
+		"standard_hours": {
+			"monday": {"open_time": "08:00", "close_time": "03:00"},
+			"tuesday": {"open_time": "08:00", "close_time": "03:00"},
+			"wednesday": {"open_time": "08:00", "close_time": "03:00"},
+			"thursday": {"open_time": "08:00", "close_time": "03:00"},
+			"friday": {"open_time": "08:00", "close_time": "03:00"},
+			"saturday": {"open_time": "", "close_time": ""},
+			"sunday": {"open_time": "", "close_time": ""}
+		},
+		"maintenance_windows": [
+			{"start_datetime": "2025-01-02T15:00:00Z", "end_datetime": "2025-01-02T16:00:00Z"}
+		]
+	}
+}`
+
+func TestExchangeScheduleParse(t *testing.T) {
+	t.Parallel()
+
+	var resp ExchangeScheduleResponse
+	require.NoError(t, json.Unmarshal([]byte(testScheduleJSON), &resp))
+	s, err := resp.Parse()
+	require.NoError(t, err)
+	require.Len(t, s.Hours, 5)
+	require.Equal(t, "08:00", s.Hours[time.Monday].Open.String())
+
+	et := s.Location
+	at := func(day, hour, minute int) time.Time {
+		// January 1st 2025 is a Wednesday.
+		return time.Date(2025, 1, day, hour, minute, 0, 0, et)
+	}
+
+	for _, tt := range []struct {
+		name          string
+		t             time.Time
+		open          bool
+		inMaintenance bool
+		nextOpen      time.Time
+		nextClose     time.Time
+	}{
+		{"wednesday morning", at(1, 9, 0), true, false, at(1, 9, 0), at(2, 3, 0)},
+		{"before open", at(1, 5, 0), false, false, at(1, 8, 0), at(1, 5, 0)},
+		{"after midnight", at(1, 2, 0), true, false, at(1, 2, 0), at(1, 3, 0)},
+		{"before maintenance", at(2, 9, 0), true, false, at(2, 9, 0), at(2, 10, 0)},
+		{"maintenance", at(2, 10, 30), false, true, at(2, 11, 0), at(2, 10, 30)},
+		{"friday night", at(4, 2, 0), true, false, at(4, 2, 0), at(4, 3, 0)},
+		{"weekend", at(4, 12, 0), false, false, at(6, 8, 0), at(4, 12, 0)},
+	} {
+		require.Equal(t, tt.open, s.IsOpen(tt.t), tt.name)
+		require.Equal(t, tt.inMaintenance, s.InMaintenance(tt.t), tt.name)
+		require.True(t, tt.nextOpen.Equal(s.NextOpen(tt.t)), "%s: got %s", tt.name, s.NextOpen(tt.t))
+		require.True(t, tt.nextClose.Equal(s.NextClose(tt.t)), "%s: got %s", tt.name, s.NextClose(tt.t))
+	}
+
+	// 08:00 stays 08:00 across the switch to daylight saving time.
+	require.Equal(t, time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC), s.NextOpen(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC).Add(7*time.Hour)).UTC())
+}
+
+func TestExchangeScheduleAlwaysOpen(t *testing.T) {
+	t.Parallel()
+
+	var resp ExchangeScheduleResponse
+	for _, hours := range []*RawTradingHours{
+		&resp.Schedule.StandardHours.Monday,
+		&resp.Schedule.StandardHours.Tuesday,
+		&resp.Schedule.StandardHours.Wednesday,
+		&resp.Schedule.StandardHours.Thursday,
+		&resp.Schedule.StandardHours.Friday,
+		&resp.Schedule.StandardHours.Saturday,
+		&resp.Schedule.StandardHours.Sunday,
+	} {
+		*hours = RawTradingHours{OpenTime: "00:00", CloseTime: "00:00"}
+	}
+	s, err := resp.Parse()
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.True(t, s.IsOpen(now))
+	require.Equal(t, now, s.NextOpen(now))
+	require.True(t, s.NextClose(now).IsZero())
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	t.Parallel()
+
+	d, err := ParseTimeOfDay("08:30")
+	require.NoError(t, err)
+	require.Equal(t, TimeOfDay(8*time.Hour+30*time.Minute), d)
+
+	d, err = ParseTimeOfDay("23:59:30")
+	require.NoError(t, err)
+	require.Equal(t, TimeOfDay(24*time.Hour-30*time.Second), d)
+
+	_, err = ParseTimeOfDay("8am")
+	require.Error(t, err)
+}
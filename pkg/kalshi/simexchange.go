@@ -0,0 +1,1036 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotSimulated is returned by the methods of SimulatedExchange that have
+// no simulated counterpart.
+var ErrNotSimulated = errors.New("not simulated")
+
+// SimulatedExchange is a KalshiClientLogic that fills orders against
+// recorded market data instead of sending them to Kalshi, for backtesting.
+//
+// Market data is applied with Apply, which also advances the exchange's
+// clock. Orders are first matched as takers against the latest order book,
+// walking it up to their limit price; the liquidity they take is gone until
+// the next book. The remainder rests behind the quantity already bid at its
+// price, and is filled as a maker by trades at its price once that queue is
+// consumed, by trades through its price, and by books crossing it. Fees are
+// charged with a FeeSchedule.
+//
+// Markets settle once their Result is known and their expiration time, or
+// close time, has passed. Result is hidden from Market until then, so
+// strategies can't peek at it.
+//
+// Order expirations are compared with the exchange's clock: orders created
+// with OrderExecuteImmediateOrCancel are immediate-or-cancel, but ExpireAfter
+// is relative to the wall clock and so isn't meaningful.
+type SimulatedExchange struct {
+	fees FeeSchedule
+	pnl  *PnLEngine
+
+	mu          sync.Mutex
+	now         time.Time
+	balance     Cents
+	markets     map[string]*simMarket
+	orders      map[string]*simOrder
+	orderIDs    []string
+	fills       []Fill
+	settlements []Settlement
+	trades      []Trade
+	stats       SimulatedStats
+
+	// fillsTaken and changed are the fills and orders not yet returned by
+	// updates.
+	fillsTaken int
+	changed    []*simOrder
+}
+
+// SimulatedStats counts the orders of a SimulatedExchange.
+type SimulatedStats struct {
+	Orders           int
+	ContractsOrdered int
+	ContractsFilled  int
+}
+
+type simMarket struct {
+	Market
+	book    OrderBook
+	hasBook bool
+	settled bool
+
+	// position is signed, Yes contracts positive, and lots are the
+	// contracts held, oldest first.
+	position int
+	lots     []simLot
+	exposure Cents
+	feesPaid Cents
+	realized Cents
+	traded   Cents
+
+	// resting are the market's resting orders, in the order placed.
+	resting []*simOrder
+}
+
+type simLot struct {
+	price Cents
+	count int
+}
+
+type simOrder struct {
+	Order
+	// side and price are those of the contracts the order buys: selling
+	// one side is buying the other at the complementary price.
+	side  Side
+	price Cents
+	// queueAhead is the number of contracts resting before the order at
+	// its price.
+	queueAhead int
+	expires    time.Time
+	// changed is set while the order is in SimulatedExchange.changed.
+	changed bool
+}
+
+var _ KalshiClientLogic = (*SimulatedExchange)(nil)
+
+// NewSimulatedExchange creates a SimulatedExchange with a starting balance,
+// trading markets. Its PnL is computed with method.
+func NewSimulatedExchange(balance Cents, fees FeeSchedule, method CostBasisMethod, markets ...Market) *SimulatedExchange {
+	e := &SimulatedExchange{
+		fees:    fees,
+		pnl:     NewPnLEngine(method, fees),
+		balance: balance,
+		markets: make(map[string]*simMarket),
+		orders:  make(map[string]*simOrder),
+	}
+	for _, m := range markets {
+		e.markets[m.Ticker] = &simMarket{Market: m}
+	}
+	return e
+}
+
+// Now returns the time of the latest market data applied.
+func (e *SimulatedExchange) Now() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.now
+}
+
+// PnL returns the engine the exchange books its fills and settlements in.
+func (e *SimulatedExchange) PnL() *PnLEngine {
+	return e.pnl
+}
+
+// Stats returns the counts of orders placed and contracts ordered and
+// filled.
+func (e *SimulatedExchange) Stats() SimulatedStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// Fills returns every fill, in order.
+func (e *SimulatedExchange) Fills() []Fill {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.fills)
+}
+
+// updates returns the fills made and the orders filled or canceled since
+// its last call, in the order they happened.
+func (e *SimulatedExchange) updates() ([]Fill, []Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	fills := slices.Clone(e.fills[e.fillsTaken:])
+	e.fillsTaken = len(e.fills)
+	orders := make([]Order, len(e.changed))
+	for i, o := range e.changed {
+		o.changed = false
+		orders[i] = o.Order
+	}
+	e.changed = nil
+	return fills, orders
+}
+
+// Apply advances the clock to ev.Time, expires orders, matches resting
+// orders against ev's book or trade and settles the markets due.
+func (e *SimulatedExchange) Apply(ev MarketEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if ev.Time.After(e.now) {
+		e.now = ev.Time
+	}
+	e.expire()
+
+	switch {
+	case ev.Book != nil:
+		m := e.market(ev.Book.MarketID)
+		m.book = OrderBook{
+			YesBids: slices.Clone(ev.Book.YesBids),
+			NoBids:  slices.Clone(ev.Book.NoBids),
+		}
+		m.hasBook = true
+		e.cross(m)
+		e.pnl.Mark(m.quoted())
+	case ev.Trade != nil:
+		e.trades = append(e.trades, *ev.Trade)
+		m := e.market(ev.Trade.Ticker)
+		m.LastPrice = ev.Trade.YesPrice
+		m.Volume += ev.Trade.Count
+		e.trade(m, *ev.Trade)
+	}
+
+	e.settleDue()
+}
+
+// SettleAll settles every market whose Result is known, regardless of its
+// expiration time, e.g. at the end of a backtest.
+func (e *SimulatedExchange) SettleAll() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range e.sortedMarkets() {
+		if m.Result != "" && !m.settled {
+			e.settle(m)
+		}
+	}
+}
+
+// Equity returns the balance plus the open positions valued at the mid
+// price, and the exposure, the cost of the open positions.
+func (e *SimulatedExchange) Equity() (equity, exposure Cents) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	equity = e.balance
+	for _, m := range e.markets {
+		if m.position == 0 {
+			continue
+		}
+		q := m.quoted()
+		equity += q.MarketValue(&MarketPosition{Position: m.position})
+		exposure += m.exposure
+	}
+	return equity, exposure
+}
+
+// ExchangeStatus reports the exchange as active.
+func (e *SimulatedExchange) ExchangeStatus(ctx context.Context) (*ExchangeStatusResponse, error) {
+	return &ExchangeStatusResponse{ExchangeActive: true, TradingActive: true}, nil
+}
+
+// ExchangeSchedule isn't simulated.
+func (e *SimulatedExchange) ExchangeSchedule(ctx context.Context) (*ExchangeScheduleResponse, error) {
+	return nil, fmt.Errorf("ExchangeSchedule: %w", ErrNotSimulated)
+}
+
+// Events isn't simulated.
+func (e *SimulatedExchange) Events(ctx context.Context, req EventsRequest) (*EventsResponse, error) {
+	return nil, fmt.Errorf("Events: %w", ErrNotSimulated)
+}
+
+// Event isn't simulated.
+func (e *SimulatedExchange) Event(ctx context.Context, event string) (*EventResponse, error) {
+	return nil, fmt.Errorf("Event: %w", ErrNotSimulated)
+}
+
+// Market returns a market with its quotes from the latest book.
+func (e *SimulatedExchange) Market(ctx context.Context, ticker string) (*Market, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	m, ok := e.markets[ticker]
+	if !ok {
+		return nil, simError(http.StatusNotFound, "market_not_found", "market "+ticker+" not found")
+	}
+	q := m.quoted()
+	return &q, nil
+}
+
+// Markets returns the markets of req.EventTicker, or all markets.
+func (e *SimulatedExchange) Markets(ctx context.Context, req MarketsRequest) (*MarketsResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resp := new(MarketsResponse)
+	for _, m := range e.sortedMarkets() {
+		if req.EventTicker != "" && m.EventTicker != req.EventTicker {
+			continue
+		}
+		resp.Markets = append(resp.Markets, m.quoted())
+	}
+	return resp, nil
+}
+
+// MarketOrderBook returns the latest book of a market, less the liquidity
+// taken since.
+func (e *SimulatedExchange) MarketOrderBook(ctx context.Context, ticker string) (*OrderBook, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	m, ok := e.markets[ticker]
+	if !ok {
+		return nil, simError(http.StatusNotFound, "market_not_found", "market "+ticker+" not found")
+	}
+	return &OrderBook{
+		YesBids: slices.Clone(m.book.YesBids),
+		NoBids:  slices.Clone(m.book.NoBids),
+	}, nil
+}
+
+// MarketHistory isn't simulated.
+func (e *SimulatedExchange) MarketHistory(ctx context.Context, ticker string, req MarketHistoryRequest) (*MarketHistoryResponse, error) {
+	return nil, fmt.Errorf("MarketHistory: %w", ErrNotSimulated)
+}
+
+// MarketCandlesticks isn't simulated.
+func (e *SimulatedExchange) MarketCandlesticks(ctx context.Context, seriesTicker, ticker string, req MarketCandlesticksRequest) (*MarketCandlesticksResponse, error) {
+	return nil, fmt.Errorf("MarketCandlesticks: %w", ErrNotSimulated)
+}
+
+// Series isn't simulated.
+func (e *SimulatedExchange) Series(ctx context.Context, seriesTicker string) (*Series, error) {
+	return nil, fmt.Errorf("Series: %w", ErrNotSimulated)
+}
+
+// GetTrades returns the trades applied so far, newest first.
+func (e *SimulatedExchange) GetTrades(ctx context.Context, req TradesRequest) (*TradesResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resp := new(TradesResponse)
+	for i := len(e.trades) - 1; i >= 0; i-- {
+		t := e.trades[i]
+		if req.Ticker != "" && t.Ticker != req.Ticker {
+			continue
+		}
+		resp.Trades = append(resp.Trades, t)
+	}
+	return resp, nil
+}
+
+// CreateOrder places an order, filling it against the latest book.
+func (e *SimulatedExchange) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if req.Count <= 0 || (req.Side != Yes && req.Side != No) || (req.Action != Buy && req.Action != Sell) {
+		return nil, simError(http.StatusBadRequest, "invalid_order", "invalid order: "+req.String())
+	}
+	m, ok := e.markets[req.Ticker]
+	if !ok {
+		return nil, simError(http.StatusNotFound, "market_not_found", "market "+req.Ticker+" not found")
+	}
+	if m.settled {
+		return nil, simError(http.StatusBadRequest, "market_closed", "market "+req.Ticker+" is closed")
+	}
+
+	price := Cents(99)
+	if req.Type != MarketOrder {
+		price = req.Price()
+		if price < 1 || price > 99 {
+			return nil, simError(http.StatusBadRequest, "invalid_order", "invalid price: "+req.String())
+		}
+	}
+	side := req.Side
+	if req.Action == Sell {
+		side, price = otherSide(side), 100-price
+		if req.Type == MarketOrder {
+			price = 99
+		}
+	}
+
+	if e.cost(m, side, price, req.Count) > e.balance-e.reserved() {
+		return nil, simError(http.StatusBadRequest, "insufficient_balance", "insufficient balance for order: "+req.String())
+	}
+
+	e.stats.Orders++
+	e.stats.ContractsOrdered += req.Count
+	o := &simOrder{
+		Order: Order{
+			Action:         req.Action,
+			ClientOrderID:  req.ClientOrderID,
+			CreatedTime:    &Time{Time: e.now},
+			LastUpdateTime: &Time{Time: e.now},
+			OrderID:        fmt.Sprintf("sim-%d", len(e.orderIDs)+1),
+			PlaceCount:     req.Count,
+			RemainingCount: req.Count,
+			Side:           req.Side,
+			Status:         Resting,
+			Ticker:         req.Ticker,
+			Type:           req.Type,
+		},
+		side:  side,
+		price: price,
+	}
+	o.setPrices()
+	if req.Expiration != nil {
+		o.expires = req.Expiration.Time()
+		o.ExpirationTime = &Time{Time: o.expires}
+	}
+	e.orders[o.OrderID] = o
+	e.orderIDs = append(e.orderIDs, o.OrderID)
+
+	e.take(m, o)
+	if o.Status == Resting {
+		if req.Type == MarketOrder || (!o.expires.IsZero() && !o.expires.After(e.now)) {
+			e.cancel(o)
+		} else {
+			o.queueAhead = m.levelQuantity(o.side, o.price)
+			o.QueuePosition = o.queueAhead
+			m.resting = append(m.resting, o)
+		}
+	}
+	order := o.Order
+	return &order, nil
+}
+
+// CancelOrder cancels a resting order.
+func (e *SimulatedExchange) CancelOrder(ctx context.Context, orderID string) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.resting(orderID)
+	if err != nil {
+		return nil, err
+	}
+	e.cancel(o)
+	order := o.Order
+	return &order, nil
+}
+
+// BatchCreateOrders creates the orders one by one.
+func (e *SimulatedExchange) BatchCreateOrders(ctx context.Context, reqs []CreateOrderRequest) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, len(reqs))
+	for i, req := range reqs {
+		results[i].ClientOrderID = req.ClientOrderID
+		results[i].Order, results[i].Err = e.CreateOrder(ctx, req)
+		if results[i].Order != nil {
+			results[i].OrderID = results[i].Order.OrderID
+		}
+	}
+	return results, nil
+}
+
+// BatchCancelOrders cancels the orders one by one.
+func (e *SimulatedExchange) BatchCancelOrders(ctx context.Context, orderIDs []string) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, len(orderIDs))
+	for i, id := range orderIDs {
+		results[i].OrderID = id
+		e.mu.Lock()
+		o, err := e.resting(id)
+		if err == nil {
+			results[i].ReducedBy = o.RemainingCount
+			e.cancel(o)
+			order := o.Order
+			results[i].Order = &order
+		}
+		e.mu.Unlock()
+		results[i].Err = err
+	}
+	return results, nil
+}
+
+// DecreaseOrder reduces the remaining count of a resting order, canceling
+// it if none remains.
+func (e *SimulatedExchange) DecreaseOrder(ctx context.Context, orderID string, req DecreaseOrderRequest) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.resting(orderID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := o.RemainingCount - req.ReduceBy
+	if req.ReduceTo > 0 || req.ReduceBy == 0 {
+		remaining = min(o.RemainingCount, req.ReduceTo)
+	}
+	if remaining <= 0 {
+		e.cancel(o)
+	} else {
+		o.DecreaseCount += o.RemainingCount - remaining
+		o.RemainingCount = remaining
+		o.LastUpdateTime = &Time{Time: e.now}
+	}
+	order := o.Order
+	return &order, nil
+}
+
+// AmendOrder changes the price and total count of a resting order. An
+// order whose price changes loses its place in the queue, and may fill
+// against the latest book.
+func (e *SimulatedExchange) AmendOrder(ctx context.Context, orderID string, req AmendOrderRequest) (*AmendOrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.resting(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Ticker != o.Ticker || req.Side != o.Side || req.Action != o.Action {
+		return nil, simError(http.StatusBadRequest, "invalid_order", "amendment doesn't match order "+orderID)
+	}
+	filled := o.TakerFillCount + o.MakerFillCount
+	if req.Count <= filled {
+		return nil, simError(http.StatusBadRequest, "invalid_order", "amended count isn't above the filled count")
+	}
+	price := req.YesPrice
+	if req.Side == No {
+		price = req.NoPrice
+	}
+	if price < 1 || price > 99 {
+		return nil, simError(http.StatusBadRequest, "invalid_order", "invalid amended price")
+	}
+
+	if req.Action == Sell {
+		price = 100 - price
+	}
+	m := e.markets[o.Ticker]
+	remaining := req.Count - filled
+	held := o.price * Cents(o.RemainingCount)
+	if price*Cents(remaining) > held && e.cost(m, o.side, price, remaining) > e.balance-e.reserved()+held {
+		return nil, simError(http.StatusBadRequest, "insufficient_balance", "insufficient balance to amend order "+orderID)
+	}
+
+	resp := &AmendOrderResponse{OldOrder: o.Order}
+	e.stats.ContractsOrdered += max(0, remaining-o.RemainingCount)
+	raised := remaining > o.RemainingCount
+	o.RemainingCount = remaining
+	o.LastUpdateTime = &Time{Time: e.now}
+	if req.UpdatedClientOrderID != "" {
+		o.ClientOrderID = req.UpdatedClientOrderID
+	}
+	e.markChanged(o)
+	// Changing the price or raising the count loses the order's place in
+	// the queue.
+	if price != o.price || raised {
+		e.unrest(o)
+		if price != o.price {
+			o.price = price
+			o.setPrices()
+			e.take(m, o)
+		}
+		if o.Status == Resting {
+			o.queueAhead = m.levelQuantity(o.side, o.price)
+			o.QueuePosition = o.queueAhead
+			m.resting = append(m.resting, o)
+		}
+	}
+	resp.Order = o.Order
+	return resp, nil
+}
+
+// GetOrder returns an order.
+func (e *SimulatedExchange) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, ok := e.orders[orderID]
+	if !ok {
+		return nil, simError(http.StatusNotFound, "order_not_found", "order "+orderID+" not found")
+	}
+	order := o.Order
+	return &order, nil
+}
+
+// GetOrders returns the orders matching req, newest first.
+func (e *SimulatedExchange) GetOrders(ctx context.Context, req OrdersRequest) (*OrdersResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resp := new(OrdersResponse)
+	for i := len(e.orderIDs) - 1; i >= 0; i-- {
+		o := e.orders[e.orderIDs[i]]
+		if (req.Ticker != "" && o.Ticker != req.Ticker) ||
+			(req.EventTicker != "" && e.markets[o.Ticker].EventTicker != req.EventTicker) ||
+			(req.Status != "" && o.Status != req.Status) {
+			continue
+		}
+		resp.Orders = append(resp.Orders, o.Order)
+	}
+	return resp, nil
+}
+
+// GetBalance returns the cash balance.
+func (e *SimulatedExchange) GetBalance(ctx context.Context) (Cents, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.balance, nil
+}
+
+// GetFills returns the fills matching req, newest first.
+func (e *SimulatedExchange) GetFills(ctx context.Context, req FillsRequest) (*FillsResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resp := new(FillsResponse)
+	for i := len(e.fills) - 1; i >= 0; i-- {
+		f := e.fills[i]
+		if (req.Ticker != "" && f.Ticker != req.Ticker) ||
+			(req.OrderID != "" && f.OrderID != req.OrderID) ||
+			(!req.MinTS.Time().IsZero() && f.CreatedTime.Before(req.MinTS.Time())) ||
+			(!req.MaxTS.Time().IsZero() && f.CreatedTime.After(req.MaxTS.Time())) {
+			continue
+		}
+		resp.Fills = append(resp.Fills, f)
+	}
+	return resp, nil
+}
+
+// GetPositions returns the positions of the markets traded.
+func (e *SimulatedExchange) GetPositions(ctx context.Context, req PositionsRequest) (*PositionsResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resp := new(PositionsResponse)
+	events := make(map[string]*EventPosition)
+	for _, m := range e.sortedMarkets() {
+		resting := m.restingCount()
+		if m.traded == 0 && resting == 0 {
+			continue
+		}
+		if (req.Ticker != "" && m.Ticker != req.Ticker) ||
+			(req.EventTicker != "" && m.EventTicker != req.EventTicker) ||
+			(req.SettlementStatus == StatusSettled && !m.settled) ||
+			((req.SettlementStatus == "" || req.SettlementStatus == StatusUnsettled) && m.settled) {
+			continue
+		}
+		resp.MarketPositions = append(resp.MarketPositions, MarketPosition{
+			FeesPaid:           m.feesPaid,
+			Position:           m.position,
+			RealizedPnl:        m.realized,
+			RestingOrdersCount: resting,
+			Ticker:             m.Ticker,
+			TotalTraded:        m.traded,
+			MarketExposure:     m.exposure,
+		})
+
+		ev, ok := events[m.EventTicker]
+		if !ok {
+			ev = &EventPosition{EventTicker: m.EventTicker}
+			events[m.EventTicker] = ev
+		}
+		ev.EventExposure += m.exposure
+		ev.FeesPaid += m.feesPaid
+		ev.RealizedPnl += m.realized
+		ev.RestingOrderCount += resting
+		ev.TotalCost += m.traded
+	}
+	for _, ev := range events {
+		resp.EventPositions = append(resp.EventPositions, *ev)
+	}
+	sort.Slice(resp.EventPositions, func(i, j int) bool {
+		return resp.EventPositions[i].EventTicker < resp.EventPositions[j].EventTicker
+	})
+	return resp, nil
+}
+
+// GetSettlements returns the settlements matching req, newest first.
+func (e *SimulatedExchange) GetSettlements(ctx context.Context, req SettlementsRequest) (*SettlementsResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resp := new(SettlementsResponse)
+	for i := len(e.settlements) - 1; i >= 0; i-- {
+		s := e.settlements[i]
+		if (req.Ticker != "" && s.Ticker != req.Ticker) ||
+			(req.EventTicker != "" && e.markets[s.Ticker].EventTicker != req.EventTicker) {
+			continue
+		}
+		resp.Settlements = append(resp.Settlements, s)
+	}
+	return resp, nil
+}
+
+func simError(code int, errorCode, message string) *HttpError {
+	err := NewHttpError(code, message)
+	err.ErrorCode = errorCode
+	return err
+}
+
+func otherSide(side Side) Side {
+	if side == Yes {
+		return No
+	}
+	return Yes
+}
+
+func (e *SimulatedExchange) market(ticker string) *simMarket {
+	m, ok := e.markets[ticker]
+	if !ok {
+		m = &simMarket{Market: Market{Ticker: ticker}}
+		e.markets[ticker] = m
+	}
+	return m
+}
+
+func (e *SimulatedExchange) sortedMarkets() []*simMarket {
+	markets := make([]*simMarket, 0, len(e.markets))
+	for _, m := range e.markets {
+		markets = append(markets, m)
+	}
+	sort.Slice(markets, func(i, j int) bool { return markets[i].Ticker < markets[j].Ticker })
+	return markets
+}
+
+func (e *SimulatedExchange) resting(orderID string) (*simOrder, error) {
+	o, ok := e.orders[orderID]
+	if !ok || o.Status != Resting {
+		return nil, simError(http.StatusNotFound, "order_not_found", "resting order "+orderID+" not found")
+	}
+	return o, nil
+}
+
+// restingOrders returns the resting orders buying side of a market, best
+// price first and then in time priority.
+func (m *simMarket) restingOrders(side Side) []*simOrder {
+	var orders []*simOrder
+	for _, o := range m.resting {
+		if o.side == side {
+			orders = append(orders, o)
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].price > orders[j].price })
+	return orders
+}
+
+func (m *simMarket) restingCount() int {
+	var n int
+	for _, o := range m.resting {
+		n += o.RemainingCount
+	}
+	return n
+}
+
+// reserved is the balance held for resting orders.
+func (e *SimulatedExchange) reserved() Cents {
+	var r Cents
+	for _, m := range e.markets {
+		for _, o := range m.resting {
+			r += o.price * Cents(o.RemainingCount)
+		}
+	}
+	return r
+}
+
+// cost is the balance needed to buy count contracts of side at price in
+// m, fees included. Contracts that close a position pay out 100 for each
+// pair, so they need no balance.
+func (e *SimulatedExchange) cost(m *simMarket, side Side, price Cents, count int) Cents {
+	opening := count
+	if held := m.heldSide(); held != "" && held != side {
+		opening = max(0, count-m.absPosition())
+	}
+	return price*Cents(opening) + e.fees.Fee(m.Ticker, price, opening, true)
+}
+
+func (e *SimulatedExchange) cancel(o *simOrder) {
+	o.Status = Canceled
+	o.RemainingCount = 0
+	o.QueuePosition = 0
+	o.LastUpdateTime = &Time{Time: e.now}
+	e.unrest(o)
+	e.markChanged(o)
+}
+
+func (e *SimulatedExchange) markChanged(o *simOrder) {
+	if !o.changed {
+		o.changed = true
+		e.changed = append(e.changed, o)
+	}
+}
+
+// unrest removes an order that is no longer resting from its market's
+// resting orders.
+func (e *SimulatedExchange) unrest(o *simOrder) {
+	if m, ok := e.markets[o.Ticker]; ok {
+		m.resting = slices.DeleteFunc(m.resting, func(r *simOrder) bool { return r == o })
+	}
+}
+
+// expire cancels the resting orders past their expiration.
+func (e *SimulatedExchange) expire() {
+	for _, m := range e.markets {
+		for _, o := range slices.Clone(m.resting) {
+			if !o.expires.IsZero() && !o.expires.After(e.now) {
+				e.cancel(o)
+			}
+		}
+	}
+}
+
+// take fills o against the offers of the latest book up to its price.
+func (e *SimulatedExchange) take(m *simMarket, o *simOrder) {
+	offers := m.bids(otherSide(o.side))
+	for i := len(*offers) - 1; i >= 0 && o.RemainingCount > 0; i-- {
+		level := &(*offers)[i]
+		price := 100 - level.Price
+		if price > o.price {
+			break
+		}
+		n := min(o.RemainingCount, level.Quantity)
+		e.fill(m, o, price, n, true)
+		level.Quantity -= n
+	}
+	*offers = slices.DeleteFunc(*offers, func(b OrderBookBid) bool { return b.Quantity <= 0 })
+}
+
+// cross fills resting orders that a new book offers at or through their
+// price, at their price, and keeps their queues no longer than the
+// quantity bid at their price.
+func (e *SimulatedExchange) cross(m *simMarket) {
+	for _, side := range []Side{Yes, No} {
+		offers := m.bids(otherSide(side))
+		for _, o := range m.restingOrders(side) {
+			for i := len(*offers) - 1; i >= 0 && o.RemainingCount > 0; i-- {
+				level := &(*offers)[i]
+				if 100-level.Price > o.price {
+					break
+				}
+				n := min(o.RemainingCount, level.Quantity)
+				e.fill(m, o, o.price, n, false)
+				level.Quantity -= n
+			}
+			*offers = slices.DeleteFunc(*offers, func(b OrderBookBid) bool { return b.Quantity <= 0 })
+			o.queueAhead = min(o.queueAhead, m.levelQuantity(o.side, o.price))
+			o.QueuePosition = o.queueAhead
+		}
+	}
+}
+
+// trade fills resting orders with a trade: the trade's maker side buys at
+// the trade's price, which first consumes the queue ahead of orders at that
+// price. Orders at better prices would have traded first.
+func (e *SimulatedExchange) trade(m *simMarket, t Trade) {
+	makerSide := otherSide(t.TakerSide)
+	makerPrice := t.YesPrice
+	if makerSide == No {
+		makerPrice = t.NoPrice
+	}
+
+	volume := t.Count
+	for _, o := range m.restingOrders(makerSide) {
+		var n int
+		switch {
+		case o.price > makerPrice:
+			n = min(o.RemainingCount, volume)
+		case o.price == makerPrice:
+			// Better priced orders, and the orders before o at its
+			// price, have taken their part of the trade.
+			ahead := min(o.queueAhead, volume)
+			o.queueAhead -= ahead
+			o.QueuePosition = o.queueAhead
+			n = min(o.RemainingCount, max(0, volume-ahead))
+		}
+		if n > 0 {
+			e.fill(m, o, o.price, n, false)
+			volume -= n
+		}
+	}
+}
+
+// fill executes count contracts of o at price, in terms of the side it
+// buys.
+func (e *SimulatedExchange) fill(m *simMarket, o *simOrder, price Cents, count int, taker bool) {
+	yesPrice := price
+	if o.side == No {
+		yesPrice = 100 - price
+	}
+	fee := e.fees.Fee(o.Ticker, yesPrice, count, taker)
+	cost := price * Cents(count)
+
+	e.balance -= cost + fee
+	e.balance += m.acquire(o.side, price, count)
+	m.feesPaid += fee
+	m.traded += cost
+
+	o.RemainingCount -= count
+	if taker {
+		o.TakerFillCount += count
+		o.TakerFillCost += cost
+		o.TakerFees += fee
+	} else {
+		o.MakerFillCount += count
+		o.MakerFillCost += int(cost)
+		o.MakerFees += fee
+	}
+	if o.RemainingCount == 0 {
+		o.Status = Executed
+		o.QueuePosition = 0
+		e.unrest(o)
+	}
+	o.LastUpdateTime = &Time{Time: e.now}
+	e.markChanged(o)
+
+	f := Fill{
+		Action:      o.Action,
+		Count:       count,
+		CreatedTime: e.now,
+		IsTaker:     taker,
+		NoPrice:     100 - yesPrice,
+		OrderID:     o.OrderID,
+		Side:        o.Side,
+		Ticker:      o.Ticker,
+		TradeID:     fmt.Sprintf("sim-fill-%d", len(e.fills)+1),
+		YesPrice:    yesPrice,
+	}
+	e.fills = append(e.fills, f)
+	e.pnl.ApplyFill(f)
+	e.stats.ContractsFilled += count
+}
+
+// settleDue settles the markets whose result is known and whose expiration
+// has passed.
+func (e *SimulatedExchange) settleDue() {
+	for _, m := range e.sortedMarkets() {
+		if m.Result == "" || m.settled {
+			continue
+		}
+		if t := m.settleTime(); !t.IsZero() && !t.After(e.now) {
+			e.settle(m)
+		}
+	}
+}
+
+// settle cancels a market's orders, pays out its position and books the
+// settlement. Results other than "yes" and "no" refund the position's cost.
+func (e *SimulatedExchange) settle(m *simMarket) {
+	for _, o := range slices.Clone(m.resting) {
+		e.cancel(o)
+	}
+
+	s := Settlement{
+		MarketResult: m.Result,
+		SettledTime:  e.now,
+		Ticker:       m.Ticker,
+	}
+	held := m.heldSide()
+	switch held {
+	case Yes:
+		s.YesCount, s.YesTotalCost = m.position, int(m.exposure)
+	case No:
+		s.NoCount, s.NoTotalCost = -m.position, int(m.exposure)
+	}
+	switch {
+	case m.Result == "yes" || m.Result == "no":
+		if Side(m.Result) == held {
+			s.Revenue = 100 * m.absPosition()
+		}
+	default:
+		s.Revenue = int(m.exposure)
+	}
+
+	e.balance += Cents(s.Revenue)
+	m.realized += Cents(s.Revenue) - m.exposure
+	m.position, m.lots, m.exposure = 0, nil, 0
+	m.settled = true
+	e.settlements = append(e.settlements, s)
+	e.pnl.ApplySettlement(s)
+}
+
+// quoted returns the market with its quotes from the latest book and its
+// result hidden until it settles.
+func (m *simMarket) quoted() Market {
+	q := m.Market
+	q.Status = "open"
+	if m.settled {
+		q.Status = "settled"
+	} else {
+		q.Result = ""
+	}
+	if m.hasBook {
+		q.YesBid, q.NoAsk, q.NoBid, q.YesAsk = 0, 100, 0, 100
+		if p, ok := m.book.BestYesBid(); ok {
+			q.YesBid, q.NoAsk = p, 100-p
+		}
+		if p, ok := m.book.BestNoBid(); ok {
+			q.NoBid, q.YesAsk = p, 100-p
+		}
+	}
+	return q
+}
+
+func (m *simMarket) bids(side Side) *OrderBookBids {
+	if side == Yes {
+		return &m.book.YesBids
+	}
+	return &m.book.NoBids
+}
+
+// levelQuantity returns the quantity bid for side at price.
+func (m *simMarket) levelQuantity(side Side, price Cents) int {
+	for _, b := range *m.bids(side) {
+		if b.Price == price {
+			return b.Quantity
+		}
+	}
+	return 0
+}
+
+// settleTime is when the market's result takes effect.
+func (m *simMarket) settleTime() time.Time {
+	if !m.ExpirationTime.IsZero() {
+		return m.ExpirationTime
+	}
+	return m.CloseTime
+}
+
+func (m *simMarket) heldSide() Side {
+	switch {
+	case m.position > 0:
+		return Yes
+	case m.position < 0:
+		return No
+	}
+	return ""
+}
+
+func (m *simMarket) absPosition() int {
+	if m.position < 0 {
+		return -m.position
+	}
+	return m.position
+}
+
+// acquire adds count contracts of side bought at price to the position
+// and returns the payout of the pairs of Yes and No contracts it nets.
+func (m *simMarket) acquire(side Side, price Cents, count int) Cents {
+	var payout Cents
+	if held := m.heldSide(); held != "" && held != side {
+		n := min(count, m.absPosition())
+		var cost Cents
+		for remaining := n; remaining > 0; {
+			l := &m.lots[0]
+			k := min(remaining, l.count)
+			cost += l.price * Cents(k)
+			l.count -= k
+			remaining -= k
+			if l.count == 0 {
+				m.lots = m.lots[1:]
+			}
+		}
+		payout = 100 * Cents(n)
+		m.realized += payout - cost - price*Cents(n)
+		m.exposure -= cost
+		if held == Yes {
+			m.position -= n
+		} else {
+			m.position += n
+		}
+		count -= n
+	}
+	if count > 0 {
+		m.lots = append(m.lots, simLot{price: price, count: count})
+		m.exposure += price * Cents(count)
+		if side == Yes {
+			m.position += count
+		} else {
+			m.position -= count
+		}
+	}
+	return payout
+}
+
+// setPrices sets the order's prices from the price of the side it buys.
+func (o *simOrder) setPrices() {
+	yes := o.price
+	if o.side == No {
+		yes = 100 - o.price
+	}
+	o.YesPrice, o.NoPrice = yes, 100-yes
+}
@@ -0,0 +1,384 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSyncInterval is how often a Runner polls trades and fills and
+	// reconciles orders by default.
+	defaultSyncInterval = time.Second
+	// defaultResubscribeDelay is how long a Runner waits before
+	// resubscribing to a book by default.
+	defaultResubscribeDelay = time.Second
+	// defaultShutdownTimeout bounds a Runner's shutdown by default.
+	defaultShutdownTimeout = 10 * time.Second
+)
+
+// Strategy reacts to market data and to its own orders. A Runner calls its
+// methods one at a time from a single goroutine, so they needn't
+// synchronize, and must return promptly. An error stops the Runner.
+//
+// Embed BaseStrategy to implement only some of the methods.
+type Strategy interface {
+	// OnBook is called with every update of the books of the Runner's
+	// tickers.
+	OnBook(ctx context.Context, r *Runner, book *StreamOrderBook) error
+	// OnTrade is called with the trades of the Runner's tickers, oldest
+	// first.
+	OnTrade(ctx context.Context, r *Runner, trade Trade) error
+	// OnFill is called with the fills of orders placed through the
+	// Runner's OrderManager.
+	OnFill(ctx context.Context, r *Runner, fill Fill) error
+	// OnOrderUpdate is called when the state of an order placed through
+	// the Runner's OrderManager changes.
+	OnOrderUpdate(ctx context.Context, r *Runner, order TrackedOrder) error
+	// OnTimer is called every Runner.TimerInterval.
+	OnTimer(ctx context.Context, r *Runner, now time.Time) error
+	// OnShutdown is the last call, made once the Runner stops and before
+	// it cancels the orders left open.
+	OnShutdown(ctx context.Context, r *Runner) error
+}
+
+// BaseStrategy implements every method of Strategy by doing nothing.
+type BaseStrategy struct{}
+
+func (BaseStrategy) OnBook(context.Context, *Runner, *StreamOrderBook) error    { return nil }
+func (BaseStrategy) OnTrade(context.Context, *Runner, Trade) error              { return nil }
+func (BaseStrategy) OnFill(context.Context, *Runner, Fill) error                { return nil }
+func (BaseStrategy) OnOrderUpdate(context.Context, *Runner, TrackedOrder) error { return nil }
+func (BaseStrategy) OnTimer(context.Context, *Runner, time.Time) error          { return nil }
+func (BaseStrategy) OnShutdown(context.Context, *Runner) error                  { return nil }
+
+// Runner runs a Strategy: it streams the books of its tickers, each from
+// its own Feed, polls their trades and the fills of the strategy's orders,
+// keeps an OrderManager reconciled, and calls the strategy with each event.
+// When it stops, it cancels every order the strategy left open.
+//
+// The strategy places orders through Orders, so the Runner can report and
+// cancel them.
+type Runner struct {
+	// TimerInterval is the interval of OnTimer calls. Zero disables them.
+	TimerInterval time.Duration
+	// SyncInterval is how often trades and fills are polled and orders
+	// reconciled. It defaults to a second.
+	SyncInterval time.Duration
+	// ResubscribeDelay is how long to wait before opening a new Feed when
+	// a book subscription fails. It defaults to a second.
+	ResubscribeDelay time.Duration
+	// ShutdownTimeout bounds OnShutdown and the cancellation of open
+	// orders. It defaults to 10 seconds.
+	ShutdownTimeout time.Duration
+
+	client   KalshiClientLogic
+	openFeed func(ctx context.Context) (*Feed, error)
+	strategy Strategy
+	tickers  []string
+	orders   *OrderManager
+	logger   *slog.Logger
+
+	// tradesFrom and seenTrades are, per ticker, the second trades are
+	// polled from and the trades already seen since.
+	tradesFrom map[string]time.Time
+	seenTrades map[string]map[string]struct{}
+}
+
+// NewRunner creates a Runner of strategy on tickers, placing orders through
+// client and streaming books from the feeds opened by openFeed, e.g.
+// Client.OpenFeed.
+func NewRunner(client KalshiClientLogic, openFeed func(ctx context.Context) (*Feed, error), strategy Strategy, tickers ...string) *Runner {
+	return &Runner{
+		client:     client,
+		openFeed:   openFeed,
+		strategy:   strategy,
+		tickers:    tickers,
+		orders:     NewOrderManager(client),
+		tradesFrom: make(map[string]time.Time),
+		seenTrades: make(map[string]map[string]struct{}),
+	}
+}
+
+// SetLogger makes the Runner log to l, as Client.SetLogger.
+func (r *Runner) SetLogger(l *slog.Logger) {
+	r.logger = redactingLogger(l)
+}
+
+func (r *Runner) log() *slog.Logger {
+	if r.logger == nil {
+		return discardLogger
+	}
+	return r.logger
+}
+
+// Client returns the client orders are placed through.
+func (r *Runner) Client() KalshiClientLogic {
+	return r.client
+}
+
+// Orders returns the OrderManager the strategy places its orders through.
+func (r *Runner) Orders() *OrderManager {
+	return r.orders
+}
+
+// Tickers returns the markets the Runner streams.
+func (r *Runner) Tickers() []string {
+	return slices.Clone(r.tickers)
+}
+
+// Run runs the strategy until ctx is done, a callback or a sync fails, or
+// every feed ends with io.EOF, e.g. replays reaching their end. It then
+// shuts down: it calls OnShutdown and cancels the orders left open. Failed
+// book subscriptions are retried on a new Feed. Rate limit errors aren't
+// considered failures.
+func (r *Runner) Run(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	books := make(chan *StreamOrderBook)
+	ended := make(chan struct{}, len(r.tickers))
+	var wg sync.WaitGroup
+	for _, ticker := range r.tickers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.stream(runCtx, ticker, books)
+			ended <- struct{}{}
+		}()
+	}
+
+	err := r.loop(runCtx, books, ended)
+	cancel()
+	wg.Wait()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.WithoutCancel(ctx), r.shutdownTimeout())
+	defer cancelShutdown()
+	return errors.Join(err, r.shutdown(shutdownCtx))
+}
+
+func (r *Runner) loop(ctx context.Context, books <-chan *StreamOrderBook, ended <-chan struct{}) error {
+	var timer <-chan time.Time
+	if r.TimerInterval > 0 {
+		t := time.NewTicker(r.TimerInterval)
+		defer t.Stop()
+		timer = t.C
+	}
+	syncInterval := r.SyncInterval
+	if syncInterval <= 0 {
+		syncInterval = defaultSyncInterval
+	}
+	syncTicker := time.NewTicker(syncInterval)
+	defer syncTicker.Stop()
+
+	streaming := len(r.tickers)
+	for done := false; !done; {
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case book := <-books:
+			err = r.strategy.OnBook(ctx, r, book)
+		case <-ended:
+			if streaming--; streaming == 0 {
+				// Trades and fills since the last sync are reported
+				// before stopping.
+				err, done = r.sync(ctx), true
+			}
+		case now := <-timer:
+			err = r.strategy.OnTimer(ctx, r, now)
+		case <-syncTicker.C:
+			err = r.sync(ctx)
+			if isRateLimited(err) {
+				r.log().LogAttrs(ctx, slog.LevelWarn, "runner sync rate limited")
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.reportOrders(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stream delivers the books of ticker until ctx is done or its feed ends
+// with io.EOF.
+func (r *Runner) stream(ctx context.Context, ticker string, books chan<- *StreamOrderBook) {
+	delay := r.ResubscribeDelay
+	if delay <= 0 {
+		delay = defaultResubscribeDelay
+	}
+	for {
+		feed, err := r.openFeed(ctx)
+		if err == nil {
+			err = feed.Book(ctx, ticker, books)
+			feed.Close()
+		}
+		if ctx.Err() != nil || errors.Is(err, io.EOF) {
+			return
+		}
+		r.log().LogAttrs(ctx, slog.LevelWarn, "resubscribing to book",
+			slog.String("ticker", ticker), slog.Any("error", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// sync reports new trades and fills and reconciles orders.
+func (r *Runner) sync(ctx context.Context) error {
+	for _, ticker := range r.tickers {
+		trades, err := r.pollTrades(ctx, ticker)
+		if err != nil {
+			return err
+		}
+		for _, trade := range trades {
+			if err := r.strategy.OnTrade(ctx, r, trade); err != nil {
+				return err
+			}
+		}
+	}
+
+	fills, err := r.orders.PollNewFills(ctx)
+	if err != nil {
+		return fmt.Errorf("r.orders.PollNewFills: %w", err)
+	}
+	for _, fill := range fills {
+		if err := r.strategy.OnFill(ctx, r, fill); err != nil {
+			return err
+		}
+	}
+
+	if err := r.orders.Reconcile(ctx); err != nil {
+		return fmt.Errorf("r.orders.Reconcile: %w", err)
+	}
+	return nil
+}
+
+// pollTrades returns the trades of ticker not yet seen, oldest first. The
+// first poll only sees trades from then on.
+func (r *Runner) pollTrades(ctx context.Context, ticker string) ([]Trade, error) {
+	from, ok := r.tradesFrom[ticker]
+	if !ok {
+		from = time.Now().Truncate(time.Second)
+		r.tradesFrom[ticker] = from
+	}
+	seen := r.seenTrades[ticker]
+	if seen == nil {
+		seen = make(map[string]struct{})
+		r.seenTrades[ticker] = seen
+	}
+
+	req := TradesRequest{Ticker: ticker, MinTS: int(from.Unix())}
+	var trades []Trade
+	for {
+		resp, err := r.client.GetTrades(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("r.client.GetTrades: %w", err)
+		}
+		trades = append(trades, resp.Trades...)
+		if resp.Cursor == "" {
+			break
+		}
+		req.Cursor = resp.Cursor
+	}
+
+	// Trades are listed newest first.
+	var fresh []Trade
+	latest := from
+	for i := len(trades) - 1; i >= 0; i-- {
+		t := trades[i]
+		if _, ok := seen[t.TradeID]; ok || t.CreatedTime.Before(from) {
+			continue
+		}
+		fresh = append(fresh, t)
+		if t.CreatedTime.After(latest) {
+			latest = t.CreatedTime
+		}
+	}
+
+	// MinTS has second precision, so polling continues from the start of
+	// the second of the latest trade, and only the trades of that second
+	// need to be remembered.
+	if next := latest.Truncate(time.Second); next.After(from) {
+		r.tradesFrom[ticker] = next
+		for id := range seen {
+			delete(seen, id)
+		}
+		for _, t := range trades {
+			if !t.CreatedTime.Before(next) {
+				seen[t.TradeID] = struct{}{}
+			}
+		}
+	} else {
+		for _, t := range fresh {
+			seen[t.TradeID] = struct{}{}
+		}
+	}
+	return fresh, nil
+}
+
+// reportOrders calls OnOrderUpdate with the orders whose state changed since
+// they were last reported, including changes made by OnOrderUpdate itself.
+func (r *Runner) reportOrders(ctx context.Context) error {
+	for {
+		changed := r.orders.takeChanged()
+		if len(changed) == 0 {
+			return nil
+		}
+		for _, order := range changed {
+			if err := r.strategy.OnOrderUpdate(ctx, r, order); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *Runner) shutdownTimeout() time.Duration {
+	if r.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return r.ShutdownTimeout
+}
+
+// shutdown calls OnShutdown and then cancels the open orders. Orders whose
+// outcome is still unknown are looked up first.
+func (r *Runner) shutdown(ctx context.Context) error {
+	err := r.strategy.OnShutdown(ctx, r)
+	if err != nil {
+		err = fmt.Errorf("OnShutdown: %w", err)
+	}
+
+	if err := r.orders.Reconcile(ctx); err != nil {
+		r.log().LogAttrs(ctx, slog.LevelWarn, "runner reconcile failed", slog.Any("error", err))
+	}
+	var orderIDs []string
+	for _, order := range r.orders.OpenOrders("", "") {
+		if order.OrderID != "" {
+			orderIDs = append(orderIDs, order.OrderID)
+		}
+	}
+	var cancelErrs []error
+	for _, result := range FanOutCancelOrders(ctx, r.client, orderIDs, cancelAllConcurrency) {
+		// Orders that are no longer resting needn't be canceled.
+		if result.Err != nil && !errors.Is(result.Err, ErrOrderNotFound) {
+			cancelErrs = append(cancelErrs, fmt.Errorf("cancel %s: %w", result.OrderID, result.Err))
+			continue
+		}
+		if result.Order != nil {
+			r.orders.ApplyOrder(result.Order)
+		}
+	}
+	r.log().LogAttrs(ctx, slog.LevelInfo, "runner stopped",
+		slog.Int("canceled", len(orderIDs)-len(cancelErrs)), slog.Int("failed", len(cancelErrs)))
+	return errors.Join(append([]error{err}, cancelErrs...)...)
+}
@@ -0,0 +1,176 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStrategy records its calls and checks they never overlap. It is
+// called from the Runner's goroutine, so it can't stop the test.
+type recordingStrategy struct {
+	t      *testing.T
+	active atomic.Int32
+	calls  []string
+
+	onBook  func(ctx context.Context, r *Runner, book *StreamOrderBook) error
+	onTimer func(ctx context.Context, r *Runner, now time.Time) error
+}
+
+func (s *recordingStrategy) record(call string) func() {
+	assert.Equal(s.t, int32(1), s.active.Add(1), "concurrent callbacks")
+	s.calls = append(s.calls, call)
+	return func() { s.active.Add(-1) }
+}
+
+func (s *recordingStrategy) OnBook(ctx context.Context, r *Runner, book *StreamOrderBook) error {
+	defer s.record(fmt.Sprintf("book %v", book.YesBids))()
+	if s.onBook != nil {
+		return s.onBook(ctx, r, book)
+	}
+	return nil
+}
+
+func (s *recordingStrategy) OnTrade(ctx context.Context, r *Runner, trade Trade) error {
+	defer s.record("trade " + trade.TradeID)()
+	return nil
+}
+
+func (s *recordingStrategy) OnFill(ctx context.Context, r *Runner, fill Fill) error {
+	defer s.record(fmt.Sprintf("fill %d", fill.Count))()
+	return nil
+}
+
+func (s *recordingStrategy) OnOrderUpdate(ctx context.Context, r *Runner, order TrackedOrder) error {
+	defer s.record(fmt.Sprintf("order %s", order.State))()
+	return nil
+}
+
+func (s *recordingStrategy) OnTimer(ctx context.Context, r *Runner, now time.Time) error {
+	defer s.record("timer")()
+	if s.onTimer != nil {
+		return s.onTimer(ctx, r, now)
+	}
+	return nil
+}
+
+func (s *recordingStrategy) OnShutdown(ctx context.Context, r *Runner) error {
+	defer s.record("shutdown")()
+	return nil
+}
+
+// newBuyingStrategy returns a recordingStrategy that buys 5 Yes of A at 40
+// on the first book, and the ID of its order once placed.
+func newBuyingStrategy(t *testing.T) (*recordingStrategy, *string) {
+	var orderID string
+	strategy := &recordingStrategy{t: t}
+	strategy.onBook = func(ctx context.Context, r *Runner, book *StreamOrderBook) error {
+		if orderID != "" {
+			return nil
+		}
+		order, err := r.Orders().Submit(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: Yes, Count: 5, YesPrice: 40, Type: LimitOrder})
+		orderID = order.OrderID
+		return err
+	}
+	return strategy, &orderID
+}
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient()
+	now := time.Now()
+	client.trades = []Trade{
+		{TradeID: "old", Ticker: "A", CreatedTime: now.Add(-time.Hour)},
+		{TradeID: "t1", Ticker: "A", CreatedTime: now.Add(time.Second)},
+	}
+	openFeed := func(context.Context) (*Feed, error) {
+		return &Feed{c: testScriptedConn(now, 0)}, nil
+	}
+
+	strategy, orderID := newBuyingStrategy(t)
+	buy := strategy.onBook
+	strategy.onBook = func(ctx context.Context, r *Runner, book *StreamOrderBook) error {
+		if len(book.YesBids) == 2 {
+			client.fill(*orderID, 2)
+		}
+		return buy(ctx, r, book)
+	}
+
+	r := NewRunner(client, openFeed, strategy, "A")
+	r.SyncInterval = time.Hour
+	require.NoError(t, r.Run(context.Background()))
+
+	// The replay ends the run, after a final sync, and the order left open
+	// is canceled.
+	require.Equal(t, []string{
+		"book [{$0.40 10}]",
+		"order resting",
+		"book [{$0.40 10} {$0.41 3}]",
+		"book [{$0.41 3}]",
+		"trade t1",
+		"fill 2",
+		"order partially_filled",
+		"shutdown",
+	}, strategy.calls)
+	order, err := client.GetOrder(context.Background(), *orderID)
+	require.NoError(t, err)
+	require.Equal(t, Canceled, order.Status)
+	tracked := r.Orders().Orders()
+	require.Len(t, tracked, 1)
+	require.Equal(t, OrderStateCanceled, tracked[0].State)
+}
+
+func TestRunnerStrategyError(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient()
+	boom := errors.New("boom")
+	strategy := &recordingStrategy{t: t}
+	strategy.onTimer = func(ctx context.Context, r *Runner, now time.Time) error {
+		if len(r.Orders().Orders()) > 0 {
+			return boom
+		}
+		_, err := r.Orders().Submit(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: No, Count: 1, NoPrice: 30, Type: LimitOrder})
+		return err
+	}
+
+	r := NewRunner(client, nil, strategy)
+	r.TimerInterval = time.Millisecond
+	r.SyncInterval = time.Hour
+	require.ErrorIs(t, r.Run(context.Background()), boom)
+	require.Equal(t, []string{"timer", "order resting", "timer", "shutdown"}, strategy.calls)
+
+	orders, err := client.GetOrders(context.Background(), OrdersRequest{Status: Resting})
+	require.NoError(t, err)
+	require.Empty(t, orders.Orders)
+}
+
+func TestRunnerCanceled(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient()
+	client.cancelErrs["order-1"] = errors.New("unavailable")
+	strategy := &recordingStrategy{t: t}
+	ctx, cancel := context.WithCancel(context.Background())
+	strategy.onTimer = func(ctx context.Context, r *Runner, now time.Time) error {
+		_, err := r.Orders().Submit(ctx, CreateOrderRequest{Ticker: "A", Action: Buy, Side: No, Count: 1, NoPrice: 30, Type: LimitOrder})
+		cancel()
+		return err
+	}
+
+	r := NewRunner(client, nil, strategy)
+	r.TimerInterval = time.Millisecond
+	r.ShutdownTimeout = time.Second
+	err := r.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	// Orders that couldn't be canceled are reported.
+	require.ErrorContains(t, err, "cancel order-1")
+	require.Equal(t, "shutdown", strategy.calls[len(strategy.calls)-1])
+}
@@ -0,0 +1,170 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// clientOrderIDNamespace is the UUID namespace for deterministic
+// ClientOrderIDs.
+var clientOrderIDNamespace = uuid.MustParse("5b0b7a4e-8f2c-4c1e-9a57-3f4f0d6c2e11")
+
+// DeterministicClientOrderID derives a ClientOrderID from parts. The same
+// parts always produce the same ID, so retrying a submission with it can't
+// place a second order.
+func DeterministicClientOrderID(parts ...string) string {
+	return uuid.NewSHA1(clientOrderIDNamespace, []byte(strings.Join(parts, "\x00"))).String()
+}
+
+const (
+	defaultSubmitAttempts      = 3
+	defaultSubmitLookupTimeout = 10 * time.Second
+	defaultSubmitRetryBackoff  = 100 * time.Millisecond
+)
+
+// OrderSubmitter places orders idempotently.
+//
+// Every order is given a deterministic ClientOrderID before it is sent. When
+// CreateOrder fails in a way that doesn't tell whether the order landed, e.g.
+// a timeout or a 5xx, the submitter looks the order up by its ClientOrderID
+// and only resubmits if it isn't found. If the lookup itself fails, or the
+// last attempt fails in the same way, an *UnknownOrderOutcomeError is
+// returned.
+type OrderSubmitter struct {
+	// MaxAttempts is the maximum number of times an order is sent. It must
+	// be at least 1.
+	MaxAttempts int
+	// LookupTimeout bounds each lookup. Lookups run even if the context
+	// passed to Submit is already done.
+	LookupTimeout time.Duration
+	// RetryBackoff is how long to wait before the first resubmission. It
+	// doubles before each one after.
+	RetryBackoff time.Duration
+
+	client    KalshiClientLogic
+	namespace string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewOrderSubmitter creates an OrderSubmitter. Generated ClientOrderIDs are
+// derived from namespace and a sequence number, so namespace should be
+// unique per process run, e.g. a bot name and its start time.
+func NewOrderSubmitter(client KalshiClientLogic, namespace string) *OrderSubmitter {
+	return &OrderSubmitter{
+		MaxAttempts:   defaultSubmitAttempts,
+		LookupTimeout: defaultSubmitLookupTimeout,
+		RetryBackoff:  defaultSubmitRetryBackoff,
+		client:        client,
+		namespace:     namespace,
+	}
+}
+
+// NextClientOrderID returns the next generated ClientOrderID.
+func (s *OrderSubmitter) NextClientOrderID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return DeterministicClientOrderID(s.namespace, strconv.Itoa(s.seq))
+}
+
+// Submit places req. If req has no ClientOrderID one is generated. The
+// returned error is an *UnknownOrderOutcomeError if the order may or may not
+// have been placed; any other error means it was not.
+func (s *OrderSubmitter) Submit(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	if s.MaxAttempts < 1 {
+		return nil, fmt.Errorf("MaxAttempts is %d, must be at least 1", s.MaxAttempts)
+	}
+	if req.ClientOrderID == "" {
+		req.ClientOrderID = s.NextClientOrderID()
+	}
+
+	var lastErr error
+	backoff := s.RetryBackoff
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			backoff *= 2
+		}
+		order, err := s.client.CreateOrder(WithAttempt(ctx, attempt), req)
+		if err == nil {
+			return order, nil
+		}
+		if !isAmbiguousOrderErr(err) {
+			return nil, fmt.Errorf("s.client.CreateOrder: %w", err)
+		}
+		lastErr = err
+
+		order, lookupErr := s.lookup(ctx, req)
+		if lookupErr != nil {
+			return nil, &UnknownOrderOutcomeError{
+				ClientOrderID: req.ClientOrderID,
+				Err:           fmt.Errorf("%w (lookup: %v)", err, lookupErr),
+			}
+		}
+		if order != nil {
+			return order, nil
+		}
+	}
+	// The last attempt may still land.
+	return nil, &UnknownOrderOutcomeError{ClientOrderID: req.ClientOrderID, Err: lastErr}
+}
+
+func (s *OrderSubmitter) lookup(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.LookupTimeout)
+	defer cancel()
+	return FindOrderByClientID(ctx, s.client, req.Ticker, req.ClientOrderID)
+}
+
+// FindOrderByClientID searches the orders for ticker for one with the given
+// ClientOrderID. It returns nil if there is none.
+func FindOrderByClientID(ctx context.Context, client KalshiClientLogic, ticker, clientOrderID string) (*Order, error) {
+	req := OrdersRequest{Ticker: ticker}
+	for {
+		resp, err := client.GetOrders(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("client.GetOrders: %w", err)
+		}
+		for i := range resp.Orders {
+			if resp.Orders[i].ClientOrderID == clientOrderID {
+				return &resp.Orders[i], nil
+			}
+		}
+		if resp.Cursor == "" {
+			return nil, nil
+		}
+		req.Cursor = resp.Cursor
+	}
+}
+
+// isAmbiguousOrderErr reports whether an order may have been placed despite
+// CreateOrder returning err.
+func isAmbiguousOrderErr(err error) bool {
+	if errors.Is(err, ErrRateLimitExceeded) {
+		// The request was never sent, or was turned away by the API's
+		// rate limiter.
+		return false
+	}
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) {
+		// A conflict means the ClientOrderID is already in use, most
+		// likely by an earlier attempt.
+		return !httpErr.IsClientErr() || httpErr.Code == http.StatusConflict
+	}
+	return true
+}
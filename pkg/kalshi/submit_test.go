@@ -0,0 +1,114 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicClientOrderID(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, DeterministicClientOrderID("bot", "1"), DeterministicClientOrderID("bot", "1"))
+	require.NotEqual(t, DeterministicClientOrderID("bot", "1"), DeterministicClientOrderID("bot", "2"))
+	require.NotEqual(t, DeterministicClientOrderID("bot1", ""), DeterministicClientOrderID("bot", "1"))
+
+	a := NewOrderSubmitter(nil, "bot")
+	b := NewOrderSubmitter(nil, "bot")
+	require.Equal(t, a.NextClientOrderID(), b.NextClientOrderID())
+	require.NotEqual(t, a.NextClientOrderID(), DeterministicClientOrderID("bot", "1"))
+}
+
+func TestOrderSubmitter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	req := CreateOrderRequest{
+		Action:   Buy,
+		Count:    1,
+		Ticker:   "A",
+		Type:     LimitOrder,
+		Side:     Yes,
+		YesPrice: 10,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		order, err := NewOrderSubmitter(client, "bot").Submit(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, DeterministicClientOrderID("bot", "1"), order.ClientOrderID)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.rejectErr = NewHttpError(http.StatusBadRequest, "insufficient_balance")
+		_, err := NewOrderSubmitter(client, "bot").Submit(ctx, req)
+		var httpErr *HttpError
+		require.ErrorAs(t, err, &httpErr)
+		require.Equal(t, 1, client.creates)
+	})
+
+	t.Run("NoAttempts", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		s := NewOrderSubmitter(client, "bot")
+		s.MaxAttempts = 0
+		_, err := s.Submit(ctx, req)
+		require.ErrorContains(t, err, "MaxAttempts")
+		require.Equal(t, 0, client.creates)
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.rejectErr = ErrRateLimitExceeded
+		_, err := NewOrderSubmitter(client, "bot").Submit(ctx, req)
+		require.ErrorIs(t, err, ErrRateLimitExceeded)
+		require.Equal(t, 1, client.creates)
+	})
+
+	t.Run("LandedDespiteError", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.createErr = errors.New("timeout")
+		order, err := NewOrderSubmitter(client, "bot").Submit(ctx, req)
+		require.NoError(t, err)
+		require.NotEmpty(t, order.OrderID)
+		require.Equal(t, 1, client.creates)
+	})
+
+	t.Run("Resubmit", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.rejectErr = NewHttpError(http.StatusServiceUnavailable, "unavailable")
+		s := NewOrderSubmitter(client, "bot")
+		s.RetryBackoff = 10 * time.Millisecond
+		start := time.Now()
+		_, err := s.Submit(ctx, req)
+		require.Equal(t, s.MaxAttempts, client.creates)
+		require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+		// The last attempt may land after the lookup.
+		var unknownErr *UnknownOrderOutcomeError
+		require.ErrorAs(t, err, &unknownErr)
+		require.ErrorContains(t, err, "unavailable")
+	})
+
+	t.Run("UnknownOutcome", func(t *testing.T) {
+		t.Parallel()
+		client := newFakeClient()
+		client.createErr = errors.New("timeout")
+		client.ordersErr = errors.New("timeout")
+		_, err := NewOrderSubmitter(client, "bot").Submit(ctx, req)
+		var unknownErr *UnknownOrderOutcomeError
+		require.ErrorAs(t, err, &unknownErr)
+		require.Equal(t, DeterministicClientOrderID("bot", "1"), unknownErr.ClientOrderID)
+		require.Equal(t, 1, client.creates)
+	})
+}
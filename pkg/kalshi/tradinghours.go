@@ -0,0 +1,298 @@
+package kalshi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrTradingPaused is returned by TradingScheduler for orders placed while
+// trading is paused.
+var ErrTradingPaused = errors.New("trading paused")
+
+// scheduleRefreshInterval is how often TradingScheduler reloads the exchange
+// schedule.
+const scheduleRefreshInterval = time.Hour
+
+// ExchangeEventType is the type of an ExchangeEvent.
+type ExchangeEventType string
+
+const (
+	// ExchangeOpened is sent when trading becomes active.
+	ExchangeOpened ExchangeEventType = "open"
+	// ExchangeClosed is sent when trading stops being active.
+	ExchangeClosed ExchangeEventType = "close"
+	// MaintenanceStarted is sent when a maintenance window starts.
+	MaintenanceStarted ExchangeEventType = "maintenance_start"
+	// MaintenanceEnded is sent when a maintenance window ends.
+	MaintenanceEnded ExchangeEventType = "maintenance_end"
+	// OrdersCanceled is sent when resting orders are canceled ahead of a
+	// maintenance window.
+	OrdersCanceled ExchangeEventType = "orders_canceled"
+)
+
+// ExchangeEvent is sent by TradingScheduler.Run.
+type ExchangeEvent struct {
+	Type ExchangeEventType
+	Time time.Time
+	// Report is set for OrdersCanceled.
+	Report *CancelAllReport
+}
+
+// TradingScheduler wraps a KalshiClientLogic and pauses order flow while the
+// exchange isn't trading. CreateOrder, BatchCreateOrders and AmendOrder are
+// gated; all other calls, including cancellations, pass through.
+//
+// Trading is considered active until Run's first poll and once Run returns,
+// and in between whenever ExchangeStatus reports TradingActive and no
+// maintenance window is near enough to cancel orders for.
+type TradingScheduler struct {
+	KalshiClientLogic
+
+	// Queue makes gated calls block until trading resumes instead of
+	// returning ErrTradingPaused.
+	Queue bool
+	// CancelBeforeMaintenance, if positive, cancels the resting orders
+	// matching CancelFilter this long before each maintenance window, and
+	// pauses trading until the window ends.
+	CancelBeforeMaintenance time.Duration
+	CancelFilter            CancelAllFilter
+
+	now    func() time.Time
+	logger *slog.Logger
+
+	mu             sync.Mutex
+	polled         bool
+	active         bool
+	resumed        chan struct{} // closed while active
+	statusActive   bool
+	inMaintenance  bool
+	schedule       *ExchangeSchedule
+	scheduleLoaded time.Time
+	canceledFor    map[time.Time]bool // by maintenance window start
+}
+
+// NewTradingScheduler creates a TradingScheduler gating orders sent through
+// client.
+func NewTradingScheduler(client KalshiClientLogic) *TradingScheduler {
+	resumed := make(chan struct{})
+	close(resumed)
+	return &TradingScheduler{
+		KalshiClientLogic: client,
+		now:               time.Now,
+		active:            true,
+		resumed:           resumed,
+		canceledFor:       make(map[time.Time]bool),
+	}
+}
+
+// SetLogger makes the TradingScheduler log to l, as Client.SetLogger.
+func (s *TradingScheduler) SetLogger(l *slog.Logger) {
+	s.logger = redactingLogger(l)
+}
+
+func (s *TradingScheduler) log() *slog.Logger {
+	if s.logger == nil {
+		return discardLogger
+	}
+	return s.logger
+}
+
+// TradingActive reports whether orders currently pass the gate.
+func (s *TradingScheduler) TradingActive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// Schedule returns the last exchange schedule loaded by Run, or nil.
+func (s *TradingScheduler) Schedule() *ExchangeSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schedule
+}
+
+// CreateOrder places req once trading is active.
+func (s *TradingScheduler) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	if err := s.await(ctx); err != nil {
+		return nil, err
+	}
+	return s.KalshiClientLogic.CreateOrder(ctx, req)
+}
+
+// BatchCreateOrders places reqs once trading is active.
+func (s *TradingScheduler) BatchCreateOrders(ctx context.Context, reqs []CreateOrderRequest) ([]BatchOrderResult, error) {
+	if err := s.await(ctx); err != nil {
+		return nil, err
+	}
+	return s.KalshiClientLogic.BatchCreateOrders(ctx, reqs)
+}
+
+// AmendOrder amends an order once trading is active.
+func (s *TradingScheduler) AmendOrder(ctx context.Context, orderID string, req AmendOrderRequest) (*AmendOrderResponse, error) {
+	if err := s.await(ctx); err != nil {
+		return nil, err
+	}
+	return s.KalshiClientLogic.AmendOrder(ctx, orderID, req)
+}
+
+func (s *TradingScheduler) await(ctx context.Context) error {
+	s.mu.Lock()
+	active, resumed := s.active, s.resumed
+	s.mu.Unlock()
+
+	if active {
+		return nil
+	}
+	if !s.Queue {
+		return ErrTradingPaused
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+// Run polls ExchangeStatus every interval, reloading the schedule hourly, and
+// updates the gate. State changes are sent to events, which may be nil.
+// Transitions are detected with up to interval of delay.
+//
+// Run returns when ctx is done or a call fails with an error other than
+// ErrRateLimitExceeded or ErrRateLimited. Failures to cancel orders ahead of
+// maintenance are logged and retried on the next poll. Once Run returns, the
+// gate is open.
+func (s *TradingScheduler) Run(ctx context.Context, interval time.Duration, events chan<- ExchangeEvent) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.setActive(true)
+	}()
+
+	for {
+		if err := s.poll(ctx, events); err != nil && !isRateLimited(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *TradingScheduler) poll(ctx context.Context, events chan<- ExchangeEvent) error {
+	now := s.now()
+
+	s.mu.Lock()
+	schedule := s.schedule
+	stale := schedule == nil || now.Sub(s.scheduleLoaded) >= scheduleRefreshInterval
+	s.mu.Unlock()
+	if stale {
+		resp, err := s.KalshiClientLogic.ExchangeSchedule(ctx)
+		if err != nil {
+			return fmt.Errorf("client.ExchangeSchedule: %w", err)
+		}
+		if schedule, err = resp.Parse(); err != nil {
+			return fmt.Errorf("resp.Parse: %w", err)
+		}
+		s.mu.Lock()
+		s.schedule, s.scheduleLoaded = schedule, now
+		s.mu.Unlock()
+	}
+
+	status, err := s.KalshiClientLogic.ExchangeStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("client.ExchangeStatus: %w", err)
+	}
+
+	var upcoming *MaintenanceWindow
+	if s.CancelBeforeMaintenance > 0 {
+		for i, w := range schedule.Maintenance {
+			if !now.Before(w.Start.Add(-s.CancelBeforeMaintenance)) && now.Before(w.End) {
+				upcoming = &schedule.Maintenance[i]
+				break
+			}
+		}
+	}
+	inMaintenance := schedule.InMaintenance(now)
+
+	s.mu.Lock()
+	var pending []ExchangeEvent
+	if !s.polled || status.TradingActive != s.statusActive {
+		typ := ExchangeClosed
+		if status.TradingActive {
+			typ = ExchangeOpened
+		}
+		pending = append(pending, ExchangeEvent{Type: typ, Time: now})
+	}
+	if inMaintenance != s.inMaintenance {
+		typ := MaintenanceEnded
+		if inMaintenance {
+			typ = MaintenanceStarted
+		}
+		pending = append(pending, ExchangeEvent{Type: typ, Time: now})
+	}
+	s.polled = true
+	s.statusActive = status.TradingActive
+	s.inMaintenance = inMaintenance
+	s.setActive(status.TradingActive && upcoming == nil)
+
+	cancel := upcoming != nil && !s.canceledFor[upcoming.Start]
+	if cancel {
+		s.canceledFor[upcoming.Start] = true
+	}
+	for start := range s.canceledFor {
+		if now.Sub(start) > 24*time.Hour {
+			delete(s.canceledFor, start)
+		}
+	}
+	s.mu.Unlock()
+
+	// The state changes above are committed, so their events are sent even
+	// if canceling fails.
+	if cancel {
+		report, err := CancelAll(ctx, s.KalshiClientLogic, s.CancelFilter)
+		if err != nil {
+			// Retry on the next poll.
+			s.mu.Lock()
+			delete(s.canceledFor, upcoming.Start)
+			s.mu.Unlock()
+			s.log().LogAttrs(ctx, slog.LevelWarn, "canceling before maintenance failed",
+				slog.Time("maintenance_start", upcoming.Start), slog.Any("error", err))
+		} else {
+			pending = append(pending, ExchangeEvent{Type: OrdersCanceled, Time: now, Report: report})
+		}
+	}
+
+	if events == nil {
+		return nil
+	}
+	for _, e := range pending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case events <- e:
+		}
+	}
+	return nil
+}
+
+// setActive opens or closes the gate. s.mu must be held.
+func (s *TradingScheduler) setActive(active bool) {
+	if active == s.active {
+		return
+	}
+	s.active = active
+	if active {
+		close(s.resumed)
+	} else {
+		s.resumed = make(chan struct{})
+	}
+}
@@ -0,0 +1,145 @@
+package kalshi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type exchangeClient struct {
+	*fakeClient
+
+	mu            sync.Mutex
+	tradingActive bool
+	schedule      ExchangeScheduleResponse
+}
+
+func (c *exchangeClient) setTradingActive(active bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tradingActive = active
+}
+
+func (c *exchangeClient) ExchangeStatus(context.Context) (*ExchangeStatusResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &ExchangeStatusResponse{ExchangeActive: true, TradingActive: c.tradingActive}, nil
+}
+
+func (c *exchangeClient) ExchangeSchedule(context.Context) (*ExchangeScheduleResponse, error) {
+	return &c.schedule, nil
+}
+
+func TestTradingScheduler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := &exchangeClient{fakeClient: newFakeClient(), tradingActive: true}
+	require.NoError(t, json.Unmarshal([]byte(testScheduleJSON), &client.schedule))
+
+	s := NewTradingScheduler(client)
+	s.CancelBeforeMaintenance = 15 * time.Minute
+	var now time.Time
+	s.now = func() time.Time { return now }
+	events := make(chan ExchangeEvent, 10)
+	next := func() ExchangeEvent {
+		select {
+		case e := <-events:
+			return e
+		default:
+			t.Fatal("no event")
+			return ExchangeEvent{}
+		}
+	}
+
+	// The maintenance window is 15:00 to 16:00 UTC.
+	now = time.Date(2025, 1, 2, 14, 0, 0, 0, time.UTC)
+	require.NoError(t, s.poll(ctx, events))
+	require.Equal(t, ExchangeEvent{Type: ExchangeOpened, Time: now}, next())
+	require.True(t, s.TradingActive())
+	resting, err := s.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Count: 1, Side: Yes})
+	require.NoError(t, err)
+
+	now = time.Date(2025, 1, 2, 14, 50, 0, 0, time.UTC)
+	require.NoError(t, s.poll(ctx, events))
+	e := next()
+	require.Equal(t, OrdersCanceled, e.Type)
+	require.Len(t, e.Report.Canceled, 1)
+	require.Equal(t, resting.OrderID, e.Report.Canceled[0].OrderID)
+	require.False(t, s.TradingActive())
+	_, err = s.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Count: 1, Side: Yes})
+	require.ErrorIs(t, err, ErrTradingPaused)
+
+	// Orders are only canceled once per window.
+	require.NoError(t, s.poll(ctx, events))
+	require.Empty(t, events)
+
+	now = time.Date(2025, 1, 2, 15, 10, 0, 0, time.UTC)
+	client.setTradingActive(false)
+	require.NoError(t, s.poll(ctx, events))
+	require.Equal(t, ExchangeClosed, next().Type)
+	require.Equal(t, MaintenanceStarted, next().Type)
+
+	s.Queue = true
+	placed := make(chan error)
+	go func() {
+		_, err := s.CreateOrder(ctx, CreateOrderRequest{Ticker: "A", Count: 1, Side: Yes})
+		placed <- err
+	}()
+	select {
+	case <-placed:
+		t.Fatal("order placed while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	now = time.Date(2025, 1, 2, 16, 5, 0, 0, time.UTC)
+	client.setTradingActive(true)
+	require.NoError(t, s.poll(ctx, events))
+	require.Equal(t, ExchangeOpened, next().Type)
+	require.Equal(t, MaintenanceEnded, next().Type)
+	require.True(t, s.TradingActive())
+	require.NoError(t, <-placed)
+}
+
+func TestTradingSchedulerCancelError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &exchangeClient{fakeClient: newFakeClient(), tradingActive: true}
+	require.NoError(t, json.Unmarshal([]byte(testScheduleJSON), &client.schedule))
+	client.ordersErr = errors.New("unavailable")
+
+	s := NewTradingScheduler(client)
+	s.CancelBeforeMaintenance = 15 * time.Minute
+	s.Queue = true
+	now := time.Date(2025, 1, 2, 14, 50, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	events := make(chan ExchangeEvent, 10)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx, time.Millisecond, events) }()
+
+	// The transition is reported even though canceling failed, and Run
+	// keeps polling to retry it.
+	require.Equal(t, ExchangeEvent{Type: ExchangeOpened, Time: now}, <-events)
+	require.False(t, s.TradingActive())
+	client.fakeClient.mu.Lock()
+	client.ordersErr = nil
+	client.fakeClient.mu.Unlock()
+	require.Equal(t, OrdersCanceled, (<-events).Type)
+
+	// Queued calls are released once Run returns.
+	placed := make(chan error)
+	go func() {
+		_, err := s.CreateOrder(context.Background(), CreateOrderRequest{Ticker: "A", Count: 1, Side: Yes})
+		placed <- err
+	}()
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.NoError(t, <-placed)
+	require.True(t, s.TradingActive())
+}
@@ -0,0 +1,156 @@
+// Package metrics exports Prometheus metrics for kalshi clients and feeds.
+//
+// It lives apart from package kalshi so that only programs that want metrics
+// depend on Prometheus.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector of kalshi client and feed metrics.
+// Attach it to a client with Instrument and register it with a
+// prometheus.Registerer.
+type Collector struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	rateLimited     *prometheus.CounterVec
+	retries         *prometheus.CounterVec
+
+	feedMessages    *prometheus.CounterVec
+	sequenceGaps    prometheus.Counter
+	reconnects      prometheus.Counter
+	deliveryDropped prometheus.Counter
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+var _ kalshi.FeedObserver = (*Collector)(nil)
+
+// New creates a Collector whose metric names are prefixed with namespace,
+// e.g. "kalshi".
+func New(namespace string) *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests sent to the Kalshi API by endpoint and status code.",
+		}, []string{"endpoint", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of Kalshi API requests by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected by the client-side rate limiter by endpoint.",
+		}, []string{"endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Requests retrying an earlier attempt by endpoint.",
+		}, []string{"endpoint"}),
+		feedMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feed_messages_total",
+			Help:      "Messages received on feeds by type.",
+		}, []string{"type"}),
+		sequenceGaps: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feed_sequence_gaps_total",
+			Help:      "Feed messages missed.",
+		}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feed_reconnects_total",
+			Help:      "Feed connections reopened after failing.",
+		}),
+		deliveryDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feed_deliveries_dropped_total",
+			Help:      "Order book updates dropped because the consumer was busy.",
+		}),
+	}
+}
+
+// Instrument adds the Collector's middleware to client and observes its
+// feeds.
+func (c *Collector) Instrument(client *kalshi.Client) {
+	client.Use(c.Middleware())
+	client.ObserveFeeds(c)
+}
+
+// Middleware returns middleware recording request metrics.
+func (c *Collector) Middleware() kalshi.Middleware {
+	return kalshi.Middleware{
+		AfterResponse: func(_ *http.Request, info kalshi.ResponseInfo) {
+			if info.Attempt > 1 {
+				c.retries.WithLabelValues(info.Name).Inc()
+			}
+			if info.StatusCode == 0 && errors.Is(info.Err, kalshi.ErrRateLimitExceeded) {
+				c.rateLimited.WithLabelValues(info.Name).Inc()
+				return
+			}
+
+			status := "error"
+			if info.StatusCode != 0 {
+				status = strconv.Itoa(info.StatusCode)
+			}
+			c.requests.WithLabelValues(info.Name, status).Inc()
+			c.requestDuration.WithLabelValues(info.Name).Observe(info.Latency.Seconds())
+		},
+	}
+}
+
+// MessageReceived implements kalshi.FeedObserver.
+func (c *Collector) MessageReceived(messageType string) {
+	c.feedMessages.WithLabelValues(messageType).Inc()
+}
+
+// SequenceGap implements kalshi.FeedObserver.
+func (c *Collector) SequenceGap(string) {
+	c.sequenceGaps.Inc()
+}
+
+// Reconnected implements kalshi.FeedObserver.
+func (c *Collector) Reconnected(string) {
+	c.reconnects.Inc()
+}
+
+// DeliveryDropped implements kalshi.FeedObserver.
+func (c *Collector) DeliveryDropped(string) {
+	c.deliveryDropped.Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.collectors() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.collectors() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.requests,
+		c.requestDuration,
+		c.rateLimited,
+		c.retries,
+		c.feedMessages,
+		c.sequenceGaps,
+		c.reconnects,
+		c.deliveryDropped,
+	}
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func testClient(t *testing.T, handler http.Handler) *kalshi.Client {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := kalshi.NewClient(srv.URL+"/", "test-key-id", "", string(keyPEM), false, 1000)
+	require.NoError(t, err)
+	return client
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/portfolio/balance" {
+			_, _ = w.Write([]byte(`{"balance": 100}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	c := New("kalshi")
+	c.Instrument(client)
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(c))
+
+	ctx := context.Background()
+	_, err := client.GetBalance(ctx)
+	require.NoError(t, err)
+	_, err = client.GetBalance(kalshi.WithAttempt(ctx, 2))
+	require.NoError(t, err)
+	_, err = client.GetOrder(ctx, "missing")
+	require.Error(t, err)
+
+	client.RateLimit = rate.NewLimiter(0, 0)
+	_, err = client.GetBalance(ctx)
+	require.ErrorIs(t, err, kalshi.ErrRateLimitExceeded)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(c.requests.WithLabelValues("GetBalance", "200")))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.requests.WithLabelValues("GetOrder", "404")))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.retries.WithLabelValues("GetBalance")))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.rateLimited.WithLabelValues("GetBalance")))
+	require.Equal(t, 2, testutil.CollectAndCount(c.requestDuration))
+
+	c.MessageReceived("orderbook_delta")
+	c.MessageReceived("orderbook_delta")
+	c.SequenceGap("A")
+	c.Reconnected("A")
+	c.DeliveryDropped("A")
+	require.Equal(t, 2.0, testutil.ToFloat64(c.feedMessages.WithLabelValues("orderbook_delta")))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.sequenceGaps))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.reconnects))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.deliveryDropped))
+
+	problems, err := testutil.GatherAndLint(registry)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
@@ -41,6 +41,51 @@ func (m *MockKalshiClientLogic) EXPECT() *MockKalshiClientLogicMockRecorder {
 	return m.recorder
 }
 
+// AmendOrder mocks base method.
+func (m *MockKalshiClientLogic) AmendOrder(ctx context.Context, orderID string, req kalshi.AmendOrderRequest) (*kalshi.AmendOrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AmendOrder", ctx, orderID, req)
+	ret0, _ := ret[0].(*kalshi.AmendOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AmendOrder indicates an expected call of AmendOrder.
+func (mr *MockKalshiClientLogicMockRecorder) AmendOrder(ctx, orderID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AmendOrder", reflect.TypeOf((*MockKalshiClientLogic)(nil).AmendOrder), ctx, orderID, req)
+}
+
+// BatchCancelOrders mocks base method.
+func (m *MockKalshiClientLogic) BatchCancelOrders(ctx context.Context, orderIDs []string) ([]kalshi.BatchOrderResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCancelOrders", ctx, orderIDs)
+	ret0, _ := ret[0].([]kalshi.BatchOrderResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchCancelOrders indicates an expected call of BatchCancelOrders.
+func (mr *MockKalshiClientLogicMockRecorder) BatchCancelOrders(ctx, orderIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCancelOrders", reflect.TypeOf((*MockKalshiClientLogic)(nil).BatchCancelOrders), ctx, orderIDs)
+}
+
+// BatchCreateOrders mocks base method.
+func (m *MockKalshiClientLogic) BatchCreateOrders(ctx context.Context, reqs []kalshi.CreateOrderRequest) ([]kalshi.BatchOrderResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreateOrders", ctx, reqs)
+	ret0, _ := ret[0].([]kalshi.BatchOrderResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchCreateOrders indicates an expected call of BatchCreateOrders.
+func (mr *MockKalshiClientLogicMockRecorder) BatchCreateOrders(ctx, reqs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreateOrders", reflect.TypeOf((*MockKalshiClientLogic)(nil).BatchCreateOrders), ctx, reqs)
+}
+
 // CancelOrder mocks base method.
 func (m *MockKalshiClientLogic) CancelOrder(ctx context.Context, orderID string) (*kalshi.Order, error) {
 	m.ctrl.T.Helper()
@@ -236,6 +281,21 @@ func (mr *MockKalshiClientLogicMockRecorder) GetSettlements(ctx, req any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettlements", reflect.TypeOf((*MockKalshiClientLogic)(nil).GetSettlements), ctx, req)
 }
 
+// GetTrades mocks base method.
+func (m *MockKalshiClientLogic) GetTrades(ctx context.Context, req kalshi.TradesRequest) (*kalshi.TradesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrades", ctx, req)
+	ret0, _ := ret[0].(*kalshi.TradesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrades indicates an expected call of GetTrades.
+func (mr *MockKalshiClientLogicMockRecorder) GetTrades(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrades", reflect.TypeOf((*MockKalshiClientLogic)(nil).GetTrades), ctx, req)
+}
+
 // Market mocks base method.
 func (m *MockKalshiClientLogic) Market(ctx context.Context, ticker string) (*kalshi.Market, error) {
 	m.ctrl.T.Helper()
@@ -251,6 +311,21 @@ func (mr *MockKalshiClientLogicMockRecorder) Market(ctx, ticker any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Market", reflect.TypeOf((*MockKalshiClientLogic)(nil).Market), ctx, ticker)
 }
 
+// MarketCandlesticks mocks base method.
+func (m *MockKalshiClientLogic) MarketCandlesticks(ctx context.Context, seriesTicker, ticker string, req kalshi.MarketCandlesticksRequest) (*kalshi.MarketCandlesticksResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarketCandlesticks", ctx, seriesTicker, ticker, req)
+	ret0, _ := ret[0].(*kalshi.MarketCandlesticksResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarketCandlesticks indicates an expected call of MarketCandlesticks.
+func (mr *MockKalshiClientLogicMockRecorder) MarketCandlesticks(ctx, seriesTicker, ticker, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarketCandlesticks", reflect.TypeOf((*MockKalshiClientLogic)(nil).MarketCandlesticks), ctx, seriesTicker, ticker, req)
+}
+
 // MarketHistory mocks base method.
 func (m *MockKalshiClientLogic) MarketHistory(ctx context.Context, ticker string, req kalshi.MarketHistoryRequest) (*kalshi.MarketHistoryResponse, error) {
 	m.ctrl.T.Helper()
@@ -310,18 +385,3 @@ func (mr *MockKalshiClientLogicMockRecorder) Series(ctx, seriesTicker any) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Series", reflect.TypeOf((*MockKalshiClientLogic)(nil).Series), ctx, seriesTicker)
 }
-
-// Trades mocks base method.
-func (m *MockKalshiClientLogic) Trades(ctx context.Context, req kalshi.TradesRequest) (*kalshi.TradesResponse, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Trades", ctx, req)
-	ret0, _ := ret[0].(*kalshi.TradesResponse)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// Trades indicates an expected call of Trades.
-func (mr *MockKalshiClientLogicMockRecorder) Trades(ctx, req any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trades", reflect.TypeOf((*MockKalshiClientLogic)(nil).Trades), ctx, req)
-}
@@ -0,0 +1,447 @@
+// Package store persists kalshi records in SQLite, so that research
+// notebooks and bots can share one local store.
+//
+// Records are stored whole as JSON alongside the columns they are queried
+// by, so fields added to the kalshi types are kept without migrations.
+//
+// It lives apart from package kalshi so that only programs that want a store
+// depend on SQLite.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned when a record isn't in the store.
+var ErrNotFound = errors.New("not found")
+
+// migrations are applied in order. The schema version is the number
+// applied, kept in the user_version pragma. Applied migrations must never
+// change.
+var migrations = []string{
+	`
+CREATE TABLE events (
+	event_ticker  TEXT PRIMARY KEY,
+	series_ticker TEXT NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX events_series ON events (series_ticker);
+
+CREATE TABLE markets (
+	ticker        TEXT NOT NULL,
+	snapshot_time INTEGER NOT NULL,
+	event_ticker  TEXT NOT NULL,
+	status        TEXT NOT NULL,
+	data          TEXT NOT NULL,
+	PRIMARY KEY (ticker, snapshot_time)
+);
+CREATE INDEX markets_event ON markets (event_ticker);
+
+CREATE TABLE trades (
+	trade_id     TEXT PRIMARY KEY,
+	ticker       TEXT NOT NULL,
+	created_time INTEGER NOT NULL,
+	data         TEXT NOT NULL
+);
+CREATE INDEX trades_ticker_time ON trades (ticker, created_time);
+
+CREATE TABLE market_history (
+	ticker TEXT NOT NULL,
+	ts     INTEGER NOT NULL,
+	data   TEXT NOT NULL,
+	PRIMARY KEY (ticker, ts)
+);
+
+CREATE TABLE orders (
+	order_id     TEXT PRIMARY KEY,
+	ticker       TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	created_time INTEGER NOT NULL,
+	data         TEXT NOT NULL
+);
+CREATE INDEX orders_ticker_time ON orders (ticker, created_time);
+
+CREATE TABLE fills (
+	trade_id     TEXT NOT NULL,
+	order_id     TEXT NOT NULL,
+	ticker       TEXT NOT NULL,
+	created_time INTEGER NOT NULL,
+	data         TEXT NOT NULL,
+	PRIMARY KEY (trade_id, order_id)
+);
+CREATE INDEX fills_ticker_time ON fills (ticker, created_time);
+
+CREATE TABLE settlements (
+	ticker       TEXT PRIMARY KEY,
+	settled_time INTEGER NOT NULL,
+	data         TEXT NOT NULL
+);
+
+CREATE TABLE positions (
+	ticker       TEXT PRIMARY KEY,
+	updated_time INTEGER NOT NULL,
+	data         TEXT NOT NULL
+);
+`,
+}
+
+// SchemaVersion is the schema version of stores opened by this package.
+var SchemaVersion = len(migrations)
+
+// Store is a SQLite store of kalshi records. It is safe for concurrent use.
+type Store struct {
+	db *sql.DB
+}
+
+var _ kalshi.DataSink = (*Store)(nil)
+
+// Open opens the SQLite database at path, creating it if needed, and
+// migrates it to SchemaVersion.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+	s, err := New(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New creates a Store using db, a SQLite database, and migrates it to
+// SchemaVersion.
+func New(ctx context.Context, db *sql.DB) (*Store, error) {
+	s := &Store{db: db}
+	if err := s.migrate(ctx); err != nil {
+		return nil, fmt.Errorf("s.migrate: %w", err)
+	}
+	return s, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read user_version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+	}
+	// PRAGMA doesn't take parameters.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", len(migrations))); err != nil {
+		return fmt.Errorf("write user_version: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Version returns the schema version of the database.
+func (s *Store) Version(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("read user_version: %w", err)
+	}
+	return version, nil
+}
+
+// unixNano returns t as stored, with the zero time as zero.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func timeOf(t *kalshi.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+// upsert executes query for every record in one transaction. args returns
+// the query's arguments for a record, which are followed by its JSON.
+func upsert[T any](ctx context.Context, s *Store, query string, records []T, args func(T) []any) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("tx.PrepareContext: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+		if _, err := stmt.ExecContext(ctx, append(args(r), string(data))...); err != nil {
+			return fmt.Errorf("stmt.ExecContext: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// query returns the records decoded from the JSON selected by query.
+func query[T any](ctx context.Context, s *Store, query string, args ...any) ([]T, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext: %w", err)
+	}
+	defer rows.Close()
+
+	var records []T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		var r T
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+	return records, nil
+}
+
+// PutEvents upserts events.
+func (s *Store) PutEvents(ctx context.Context, events ...kalshi.Event) error {
+	return upsert(ctx, s, `
+INSERT INTO events (event_ticker, series_ticker, data) VALUES (?, ?, ?)
+ON CONFLICT (event_ticker) DO UPDATE SET series_ticker = excluded.series_ticker, data = excluded.data`,
+		events, func(e kalshi.Event) []any { return []any{e.EventTicker, e.SeriesTicker} })
+}
+
+// Event returns the event with ticker eventTicker.
+func (s *Store) Event(ctx context.Context, eventTicker string) (*kalshi.Event, error) {
+	events, err := query[kalshi.Event](ctx, s, `SELECT data FROM events WHERE event_ticker = ?`, eventTicker)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return &events[0], nil
+}
+
+// PutMarkets stores snapshots of markets taken at at, replacing any taken
+// at the same time.
+func (s *Store) PutMarkets(ctx context.Context, at time.Time, markets ...kalshi.Market) error {
+	return upsert(ctx, s, `
+INSERT INTO markets (ticker, snapshot_time, event_ticker, status, data) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (ticker, snapshot_time) DO UPDATE SET
+	event_ticker = excluded.event_ticker, status = excluded.status, data = excluded.data`,
+		markets, func(m kalshi.Market) []any { return []any{m.Ticker, unixNano(at), m.EventTicker, m.Status} })
+}
+
+// MarketSnapshot is a Market as it was at a time.
+type MarketSnapshot struct {
+	At     time.Time
+	Market kalshi.Market
+}
+
+// LatestMarkets returns the latest snapshot of every market, by ticker.
+func (s *Store) LatestMarkets(ctx context.Context) ([]MarketSnapshot, error) {
+	return s.marketSnapshots(ctx, `
+SELECT m.snapshot_time, m.data FROM markets m
+JOIN (SELECT ticker, MAX(snapshot_time) AS snapshot_time FROM markets GROUP BY ticker) latest
+	USING (ticker, snapshot_time)
+ORDER BY m.ticker`)
+}
+
+// LatestMarket returns the latest snapshot of the market with ticker.
+func (s *Store) LatestMarket(ctx context.Context, ticker string) (*MarketSnapshot, error) {
+	snapshots, err := s.marketSnapshots(ctx, `
+SELECT snapshot_time, data FROM markets WHERE ticker = ?
+ORDER BY snapshot_time DESC LIMIT 1`, ticker)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrNotFound
+	}
+	return &snapshots[0], nil
+}
+
+func (s *Store) marketSnapshots(ctx context.Context, query string, args ...any) ([]MarketSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []MarketSnapshot
+	for rows.Next() {
+		var (
+			at   int64
+			data string
+		)
+		if err := rows.Scan(&at, &data); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		snapshot := MarketSnapshot{At: time.Unix(0, at).UTC()}
+		if err := json.Unmarshal([]byte(data), &snapshot.Market); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+	return snapshots, nil
+}
+
+// PutTrades upserts trades by TradeID.
+func (s *Store) PutTrades(ctx context.Context, trades ...kalshi.Trade) error {
+	return upsert(ctx, s, `
+INSERT INTO trades (trade_id, ticker, created_time, data) VALUES (?, ?, ?, ?)
+ON CONFLICT (trade_id) DO UPDATE SET
+	ticker = excluded.ticker, created_time = excluded.created_time, data = excluded.data`,
+		trades, func(t kalshi.Trade) []any { return []any{t.TradeID, t.Ticker, unixNano(t.CreatedTime)} })
+}
+
+// Trades returns the trades of the market with ticker made in [from, to),
+// oldest first. A zero to is unbounded.
+func (s *Store) Trades(ctx context.Context, ticker string, from, to time.Time) ([]kalshi.Trade, error) {
+	return query[kalshi.Trade](ctx, s, `
+SELECT data FROM trades WHERE ticker = ? AND created_time >= ? AND (? = 0 OR created_time < ?)
+ORDER BY created_time, trade_id`, ticker, unixNano(from), unixNano(to), unixNano(to))
+}
+
+// WriteTrades implements kalshi.DataSink.
+func (s *Store) WriteTrades(ctx context.Context, _ string, trades []kalshi.Trade) error {
+	return s.PutTrades(ctx, trades...)
+}
+
+// PutHistory upserts the history of the market with ticker by timestamp.
+func (s *Store) PutHistory(ctx context.Context, ticker string, history ...kalshi.MarketHistory) error {
+	return upsert(ctx, s, `
+INSERT INTO market_history (ticker, ts, data) VALUES (?, ?, ?)
+ON CONFLICT (ticker, ts) DO UPDATE SET data = excluded.data`,
+		history, func(h kalshi.MarketHistory) []any { return []any{ticker, unixNano(h.Ts.Time())} })
+}
+
+// History returns the history of the market with ticker in [from, to),
+// oldest first. A zero to is unbounded.
+func (s *Store) History(ctx context.Context, ticker string, from, to time.Time) ([]kalshi.MarketHistory, error) {
+	return query[kalshi.MarketHistory](ctx, s, `
+SELECT data FROM market_history WHERE ticker = ? AND ts >= ? AND (? = 0 OR ts < ?)
+ORDER BY ts`, ticker, unixNano(from), unixNano(to), unixNano(to))
+}
+
+// WriteHistory implements kalshi.DataSink.
+func (s *Store) WriteHistory(ctx context.Context, ticker string, history []kalshi.MarketHistory) error {
+	return s.PutHistory(ctx, ticker, history...)
+}
+
+// PutOrders upserts orders by OrderID.
+func (s *Store) PutOrders(ctx context.Context, orders ...kalshi.Order) error {
+	return upsert(ctx, s, `
+INSERT INTO orders (order_id, ticker, status, created_time, data) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (order_id) DO UPDATE SET
+	ticker = excluded.ticker, status = excluded.status,
+	created_time = excluded.created_time, data = excluded.data`,
+		orders, func(o kalshi.Order) []any {
+			return []any{o.OrderID, o.Ticker, string(o.Status), unixNano(timeOf(o.CreatedTime))}
+		})
+}
+
+// Order returns the order with orderID.
+func (s *Store) Order(ctx context.Context, orderID string) (*kalshi.Order, error) {
+	orders, err := query[kalshi.Order](ctx, s, `SELECT data FROM orders WHERE order_id = ?`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrNotFound
+	}
+	return &orders[0], nil
+}
+
+// Orders returns the orders of the market with ticker, oldest first, or of
+// every market if ticker is empty.
+func (s *Store) Orders(ctx context.Context, ticker string) ([]kalshi.Order, error) {
+	return query[kalshi.Order](ctx, s, `
+SELECT data FROM orders WHERE ? = '' OR ticker = ?
+ORDER BY created_time, order_id`, ticker, ticker)
+}
+
+// PutFills upserts fills by TradeID and OrderID.
+func (s *Store) PutFills(ctx context.Context, fills ...kalshi.Fill) error {
+	return upsert(ctx, s, `
+INSERT INTO fills (trade_id, order_id, ticker, created_time, data) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (trade_id, order_id) DO UPDATE SET
+	ticker = excluded.ticker, created_time = excluded.created_time, data = excluded.data`,
+		fills, func(f kalshi.Fill) []any { return []any{f.TradeID, f.OrderID, f.Ticker, unixNano(f.CreatedTime)} })
+}
+
+// Fills returns the fills in the market with ticker made in [from, to),
+// oldest first. A zero to is unbounded.
+func (s *Store) Fills(ctx context.Context, ticker string, from, to time.Time) ([]kalshi.Fill, error) {
+	return query[kalshi.Fill](ctx, s, `
+SELECT data FROM fills WHERE ticker = ? AND created_time >= ? AND (? = 0 OR created_time < ?)
+ORDER BY created_time, trade_id, order_id`, ticker, unixNano(from), unixNano(to), unixNano(to))
+}
+
+// PutSettlements upserts settlements by ticker.
+func (s *Store) PutSettlements(ctx context.Context, settlements ...kalshi.Settlement) error {
+	return upsert(ctx, s, `
+INSERT INTO settlements (ticker, settled_time, data) VALUES (?, ?, ?)
+ON CONFLICT (ticker) DO UPDATE SET settled_time = excluded.settled_time, data = excluded.data`,
+		settlements, func(st kalshi.Settlement) []any { return []any{st.Ticker, unixNano(st.SettledTime)} })
+}
+
+// Settlements returns every settlement, oldest first.
+func (s *Store) Settlements(ctx context.Context) ([]kalshi.Settlement, error) {
+	return query[kalshi.Settlement](ctx, s, `SELECT data FROM settlements ORDER BY settled_time, ticker`)
+}
+
+// PutPositions upserts positions by ticker, as of at. Positions older than
+// the stored ones are ignored.
+func (s *Store) PutPositions(ctx context.Context, at time.Time, positions ...kalshi.MarketPosition) error {
+	return upsert(ctx, s, `
+INSERT INTO positions (ticker, updated_time, data) VALUES (?, ?, ?)
+ON CONFLICT (ticker) DO UPDATE SET updated_time = excluded.updated_time, data = excluded.data
+WHERE excluded.updated_time >= positions.updated_time`,
+		positions, func(p kalshi.MarketPosition) []any { return []any{p.Ticker, unixNano(at)} })
+}
+
+// Positions returns the latest position in every market, by ticker.
+func (s *Store) Positions(ctx context.Context) ([]kalshi.MarketPosition, error) {
+	return query[kalshi.MarketPosition](ctx, s, `SELECT data FROM positions ORDER BY ticker`)
+}
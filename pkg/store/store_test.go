@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T) (*Store, string) {
+	path := filepath.Join(t.TempDir(), "kalshi.db")
+	s, err := Open(context.Background(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, path := testStore(t)
+	version, err := s.Version(ctx)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, version)
+
+	require.NoError(t, s.PutEvents(ctx, kalshi.Event{EventTicker: "S-1", SeriesTicker: "S"}))
+	require.NoError(t, s.Close())
+
+	// Reopening keeps the data and doesn't migrate again.
+	s, err = Open(ctx, path)
+	require.NoError(t, err)
+	defer s.Close()
+	event, err := s.Event(ctx, "S-1")
+	require.NoError(t, err)
+	require.Equal(t, "S", event.SeriesTicker)
+
+	_, err = s.Event(ctx, "S-2")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMarkets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, _ := testStore(t)
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.PutMarkets(ctx, t0,
+		kalshi.Market{Ticker: "A", Status: "open", YesBid: 40},
+		kalshi.Market{Ticker: "B", Status: "open", YesBid: 10},
+	))
+	require.NoError(t, s.PutMarkets(ctx, t0.Add(time.Minute), kalshi.Market{Ticker: "A", Status: "closed", YesBid: 45}))
+	// Upserting the same snapshot replaces it.
+	require.NoError(t, s.PutMarkets(ctx, t0.Add(time.Minute), kalshi.Market{Ticker: "A", Status: "closed", YesBid: 50}))
+
+	latest, err := s.LatestMarkets(ctx)
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	require.Equal(t, t0.Add(time.Minute), latest[0].At)
+	require.Equal(t, kalshi.Cents(50), latest[0].Market.YesBid)
+	require.Equal(t, "B", latest[1].Market.Ticker)
+
+	snapshot, err := s.LatestMarket(ctx, "B")
+	require.NoError(t, err)
+	require.Equal(t, t0, snapshot.At)
+	_, err = s.LatestMarket(ctx, "C")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFillsAndTrades(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, _ := testStore(t)
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	fill := func(tradeID string, at time.Time) kalshi.Fill {
+		return kalshi.Fill{TradeID: tradeID, OrderID: "o", Ticker: "A", Count: 1, YesPrice: 40, CreatedTime: at}
+	}
+	require.NoError(t, s.PutFills(ctx,
+		fill("3", t0.Add(2*time.Hour)),
+		fill("1", t0),
+		fill("2", t0.Add(time.Hour)),
+		kalshi.Fill{TradeID: "4", OrderID: "o", Ticker: "B", CreatedTime: t0},
+	))
+	// Upserts don't duplicate.
+	require.NoError(t, s.PutFills(ctx, fill("1", t0)))
+
+	fills, err := s.Fills(ctx, "A", t0, t0.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []kalshi.Fill{fill("1", t0), fill("2", t0.Add(time.Hour))}, fills)
+	fills, err = s.Fills(ctx, "A", t0.Add(time.Hour), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, fills, 2)
+
+	// Store is a kalshi.DataSink.
+	var sink kalshi.DataSink = s
+	trades := []kalshi.Trade{
+		{TradeID: "t1", Ticker: "A", YesPrice: 40, NoPrice: 60, Count: 2, CreatedTime: t0, TakerSide: kalshi.Yes},
+		{TradeID: "t2", Ticker: "A", YesPrice: 41, NoPrice: 59, Count: 1, CreatedTime: t0.Add(time.Second), TakerSide: kalshi.No},
+	}
+	require.NoError(t, sink.WriteTrades(ctx, "A", trades))
+	require.NoError(t, sink.WriteTrades(ctx, "A", trades[1:]))
+	got, err := s.Trades(ctx, "A", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, trades, got)
+
+	history := []kalshi.MarketHistory{{Ts: kalshi.Timestamp(t0), YesBid: 40}, {Ts: kalshi.Timestamp(t0.Add(time.Hour)), YesBid: 41}}
+	require.NoError(t, sink.WriteHistory(ctx, "A", history))
+	gotHistory, err := s.History(ctx, "A", t0.Add(time.Minute), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, gotHistory, 1)
+	require.Equal(t, kalshi.Cents(41), gotHistory[0].YesBid)
+}
+
+func TestOrdersSettlementsPositions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, _ := testStore(t)
+	t0 := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.PutOrders(ctx,
+		kalshi.Order{OrderID: "o1", Ticker: "A", Status: kalshi.Resting, CreatedTime: &kalshi.Time{Time: t0}},
+		kalshi.Order{OrderID: "o2", Ticker: "B", Status: kalshi.Resting},
+	))
+	require.NoError(t, s.PutOrders(ctx, kalshi.Order{OrderID: "o1", Ticker: "A", Status: kalshi.Executed, CreatedTime: &kalshi.Time{Time: t0}}))
+	order, err := s.Order(ctx, "o1")
+	require.NoError(t, err)
+	require.Equal(t, kalshi.Executed, order.Status)
+	orders, err := s.Orders(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	orders, err = s.Orders(ctx, "B")
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	require.NoError(t, s.PutSettlements(ctx, kalshi.Settlement{Ticker: "A", MarketResult: "yes", SettledTime: t0}))
+	settlements, err := s.Settlements(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []kalshi.Settlement{{Ticker: "A", MarketResult: "yes", SettledTime: t0}}, settlements)
+
+	require.NoError(t, s.PutPositions(ctx, t0, kalshi.MarketPosition{Ticker: "A", Position: 5}))
+	// Older positions don't replace newer ones.
+	require.NoError(t, s.PutPositions(ctx, t0.Add(-time.Hour), kalshi.MarketPosition{Ticker: "A", Position: 1}))
+	positions, err := s.Positions(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []kalshi.MarketPosition{{Ticker: "A", Position: 5}}, positions)
+}
@@ -0,0 +1,152 @@
+// Package tracing traces kalshi client requests and feed subscriptions with
+// OpenTelemetry.
+//
+// It lives apart from package kalshi so that only programs that want traces
+// depend on OpenTelemetry.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the spans created by this package.
+const InstrumentationName = "github.com/ggarcia209/kalshi/pkg/tracing"
+
+// Span attributes specific to Kalshi.
+const (
+	EndpointKey    = attribute.Key("kalshi.endpoint")
+	TickerKey      = attribute.Key("kalshi.ticker")
+	OrderIDKey     = attribute.Key("kalshi.order_id")
+	AttemptKey     = attribute.Key("kalshi.attempt")
+	RateLimitedKey = attribute.Key("kalshi.rate_limited")
+	ChannelKey     = attribute.Key("kalshi.channel")
+)
+
+// Tracer creates spans for kalshi client requests and feed subscriptions.
+// Attach it to a client with Instrument.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var (
+	_ kalshi.FeedObserver         = (*Tracer)(nil)
+	_ kalshi.SubscriptionObserver = (*Tracer)(nil)
+)
+
+// New creates a Tracer using tp, or the global TracerProvider if tp is nil.
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(InstrumentationName)}
+}
+
+// Instrument adds the Tracer's middleware to client and observes its feeds.
+func (t *Tracer) Instrument(client *kalshi.Client) {
+	client.Use(t.Middleware())
+	client.ObserveFeeds(t)
+}
+
+type spanKey struct{}
+
+// Middleware returns middleware creating a span for every request, as a
+// child of the span in the caller's context. Requests rejected by the rate
+// limiter are recorded as empty spans with kalshi.rate_limited set.
+func (t *Tracer) Middleware() kalshi.Middleware {
+	return kalshi.Middleware{
+		BeforeRequest: func(req *http.Request, info kalshi.RequestInfo) (*http.Request, error) {
+			ctx, span := t.tracer.Start(req.Context(), spanName(info),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(info)...),
+			)
+			ctx = context.WithValue(ctx, spanKey{}, span)
+			return req.WithContext(ctx), nil
+		},
+		AfterResponse: func(req *http.Request, info kalshi.ResponseInfo) {
+			span, ok := req.Context().Value(spanKey{}).(trace.Span)
+			if !ok {
+				now := time.Now()
+				_, span = t.tracer.Start(req.Context(), spanName(info.RequestInfo),
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(requestAttributes(info.RequestInfo)...),
+					trace.WithTimestamp(now),
+				)
+				span.SetAttributes(RateLimitedKey.Bool(errors.Is(info.Err, kalshi.ErrRateLimitExceeded)))
+				defer span.End(trace.WithTimestamp(now))
+			} else {
+				defer span.End()
+			}
+
+			if info.StatusCode != 0 {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(info.StatusCode))
+			}
+			if info.Err != nil {
+				span.RecordError(info.Err)
+				span.SetStatus(codes.Error, info.Err.Error())
+			}
+		},
+	}
+}
+
+func spanName(info kalshi.RequestInfo) string {
+	return "kalshi." + info.Name
+}
+
+func requestAttributes(info kalshi.RequestInfo) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(info.Method),
+		EndpointKey.String(info.Endpoint),
+		AttemptKey.Int(info.Attempt),
+	}
+	if info.Ticker != "" {
+		attrs = append(attrs, TickerKey.String(info.Ticker))
+	}
+	if info.OrderID != "" {
+		attrs = append(attrs, OrderIDKey.String(info.OrderID))
+	}
+	return attrs
+}
+
+// Subscribe implements kalshi.SubscriptionObserver, creating a span lasting
+// as long as the subscription.
+func (t *Tracer) Subscribe(ctx context.Context, channel, marketTicker string) (context.Context, func(err error)) {
+	ctx, span := t.tracer.Start(ctx, "kalshi.Subscribe",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			ChannelKey.String(channel),
+			TickerKey.String(marketTicker),
+		),
+	)
+	return ctx, func(err error) {
+		// Subscriptions end when the caller cancels them.
+		if err != nil && !errors.Is(err, context.Canceled) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// MessageReceived implements kalshi.FeedObserver. It does nothing.
+func (t *Tracer) MessageReceived(string) {}
+
+// SequenceGap implements kalshi.FeedObserver. It does nothing; the gap ends
+// the subscription's span with an error.
+func (t *Tracer) SequenceGap(string) {}
+
+// Reconnected implements kalshi.FeedObserver. It does nothing; the
+// reconnection is part of the subscription's span.
+func (t *Tracer) Reconnected(string) {}
+
+// DeliveryDropped implements kalshi.FeedObserver. It does nothing.
+func (t *Tracer) DeliveryDropped(string) {}
@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ggarcia209/kalshi/pkg/kalshi"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+func testClient(t *testing.T, handler http.Handler) *kalshi.Client {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := kalshi.NewClient(srv.URL+"/", "test-key-id", "", string(keyPEM), false, 1000)
+	require.NoError(t, err)
+	return client
+}
+
+func testTracer() (*Tracer, *tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return New(tp), exporter, tp
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracerRequests(t *testing.T) {
+	t.Parallel()
+
+	var traceparent []string
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = append(traceparent, r.Header.Get("Traceparent"))
+		if r.URL.Path == "/markets/A" {
+			_, _ = w.Write([]byte(`{"market": {"ticker": "A"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	tracer, exporter, tp := testTracer()
+	tracer.Instrument(client)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.Market(ctx, "A")
+	require.NoError(t, err)
+	_, err = client.GetOrder(ctx, "missing")
+	require.ErrorIs(t, err, kalshi.ErrOrderNotFound)
+
+	client.RateLimit = rate.NewLimiter(0, 0)
+	_, err = client.GetBalance(ctx)
+	require.ErrorIs(t, err, kalshi.ErrRateLimitExceeded)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+	for _, span := range spans[:3] {
+		require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		require.Equal(t, trace.SpanKindClient, span.SpanKind)
+	}
+	// Tracing doesn't propagate the context to the server by itself.
+	require.Equal(t, []string{"", ""}, traceparent)
+
+	market := spans[0]
+	require.Equal(t, "kalshi.Market", market.Name)
+	require.Equal(t, codes.Unset, market.Status.Code)
+	attrs := attributes(market)
+	require.Equal(t, "GET", attrs[semconv.HTTPRequestMethodKey].AsString())
+	require.Equal(t, "markets/A", attrs[EndpointKey].AsString())
+	require.Equal(t, "A", attrs[TickerKey].AsString())
+	require.Equal(t, int64(http.StatusOK), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	require.Equal(t, int64(1), attrs[AttemptKey].AsInt64())
+
+	order := spans[1]
+	require.Equal(t, "kalshi.GetOrder", order.Name)
+	require.Equal(t, codes.Error, order.Status.Code)
+	attrs = attributes(order)
+	require.Equal(t, "missing", attrs[OrderIDKey].AsString())
+	require.Equal(t, int64(http.StatusNotFound), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	require.Len(t, order.Events, 1)
+
+	limited := spans[2]
+	require.Equal(t, "kalshi.GetBalance", limited.Name)
+	require.Equal(t, codes.Error, limited.Status.Code)
+	require.True(t, attributes(limited)[RateLimitedKey].AsBool())
+	require.Equal(t, limited.StartTime, limited.EndTime)
+}
+
+func TestTracerSubscribe(t *testing.T) {
+	t.Parallel()
+
+	tracer, exporter, _ := testTracer()
+
+	ctx, end := tracer.Subscribe(context.Background(), "orderbook_delta", "A")
+	require.True(t, trace.SpanContextFromContext(ctx).IsValid())
+	end(context.Canceled)
+
+	_, end = tracer.Subscribe(context.Background(), "orderbook_delta", "B")
+	end(errors.New("unexpected sequence 3, want 2"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "kalshi.Subscribe", spans[0].Name)
+	require.Equal(t, trace.SpanKindConsumer, spans[0].SpanKind)
+	require.Equal(t, "orderbook_delta", attributes(spans[0])[ChannelKey].AsString())
+	require.Equal(t, "A", attributes(spans[0])[TickerKey].AsString())
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+}